@@ -51,7 +51,7 @@ func main() {
 	}
 	fmt.Println(vmByName)
 
-	err = vmByName.UpdatePowerState("on")
+	err = vmByName.Power(ovc.PowerOn)
 	fmt.Println(err)
 
 	//Get a VM resource by its id
@@ -0,0 +1,259 @@
+package ovc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	pathpkg "path"
+	"strconv"
+)
+
+// VirtualDisk is a virtual disk of the VM in a backup.
+type VirtualDisk struct {
+	Name string
+
+	// Backup the disk belongs to
+	backup *Backup
+}
+
+// DiskPartition is a partition of a virtual disk in a backup.
+type DiskPartition struct {
+	Number         int    `json:"partition_number"`
+	Size           int64  `json:"size,omitempty"`
+	FileSystemType string `json:"file_system_type,omitempty"`
+	Mountable      bool   `json:"mountable,omitempty"`
+
+	// Virtual disk the partition belongs to
+	disk *VirtualDisk
+}
+
+// BackupFile is a file or a folder of a partition in a backup.
+type BackupFile struct {
+	Name         string `json:"name"`
+	Directory    bool   `json:"directory,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// Folder of the file in the partition
+	dir string
+
+	// Partition the file belongs to
+	partition *DiskPartition
+}
+
+// VirtualDisks lists the virtual disks of the VM in the backup.
+func (b *Backup) VirtualDisks() ([]*VirtualDisk, error) {
+	return b.VirtualDisksWithContext(context.Background())
+}
+
+// VirtualDisksWithContext is like VirtualDisks but uses ctx for the requests to the OVC.
+func (b *Backup) VirtualDisksWithContext(ctx context.Context) ([]*VirtualDisk, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path  = fmt.Sprintf("/backups/%s/virtual_disks", b.Id)
+		names struct {
+			VirtualDisks []string `json:"virtual_disks"`
+		}
+	)
+
+	resp, err := b.client.DoRequestWithContext(ctx, "GET", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(resp, &names)
+	if err != nil {
+		return nil, err
+	}
+
+	disks := make([]*VirtualDisk, len(names.VirtualDisks))
+	for i, name := range names.VirtualDisks {
+		disks[i] = &VirtualDisk{Name: name, backup: b}
+	}
+
+	return disks, nil
+}
+
+// Partitions lists the partitions of the virtual disk.
+func (d *VirtualDisk) Partitions() ([]*DiskPartition, error) {
+	return d.PartitionsWithContext(context.Background())
+}
+
+// PartitionsWithContext is like Partitions but uses ctx for the requests to the OVC.
+func (d *VirtualDisk) PartitionsWithContext(ctx context.Context) ([]*DiskPartition, error) {
+	if d.backup == nil || d.backup.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path       = fmt.Sprintf("/backups/%s/virtual_disk_partitions", d.backup.Id)
+		queryStr   = url.Values{"virtual_disk": {d.Name}}.Encode()
+		partitions struct {
+			Partitions []*DiskPartition `json:"partitions"`
+		}
+	)
+
+	resp, err := d.backup.client.DoRequestWithContext(ctx, "GET", path, queryStr, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(resp, &partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partitions.Partitions {
+		partition.disk = d
+	}
+
+	return partitions.Partitions, nil
+}
+
+// Files lists the files and folders at the root of the partition.
+func (p *DiskPartition) Files() ([]*BackupFile, error) {
+	return p.FilesWithContext(context.Background())
+}
+
+// FilesWithContext is like Files but uses ctx for the requests to the OVC.
+func (p *DiskPartition) FilesWithContext(ctx context.Context) ([]*BackupFile, error) {
+	return p.listFiles(ctx, "/")
+}
+
+// Files lists the files and folders in the folder.
+// Returns an empty list for files.
+func (f *BackupFile) Files() ([]*BackupFile, error) {
+	return f.FilesWithContext(context.Background())
+}
+
+// FilesWithContext is like Files but uses ctx for the requests to the OVC.
+func (f *BackupFile) FilesWithContext(ctx context.Context) ([]*BackupFile, error) {
+	if !f.Directory {
+		return []*BackupFile{}, nil
+	}
+
+	if f.partition == nil {
+		return nil, ErrNoClient
+	}
+
+	return f.partition.listFiles(ctx, f.FilePath())
+}
+
+// listFiles lists the files and folders in the folder dir of the partition.
+func (p *DiskPartition) listFiles(ctx context.Context, dir string) ([]*BackupFile, error) {
+	if p.disk == nil || p.disk.backup == nil || p.disk.backup.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		backup   = p.disk.backup
+		path     = fmt.Sprintf("/backups/%s/virtual_disk_partition_files", backup.Id)
+		queryStr = url.Values{
+			"virtual_disk":     {p.disk.Name},
+			"partition_number": {strconv.Itoa(p.Number)},
+			"file_path":        {dir},
+		}.Encode()
+		files struct {
+			Files []*BackupFile `json:"virtual_disk_partition_files"`
+		}
+	)
+
+	resp, err := backup.client.DoRequestWithContext(ctx, "GET", path, queryStr, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(resp, &files)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files.Files {
+		file.dir = dir
+		file.partition = p
+	}
+
+	return files.Files, nil
+}
+
+// FilePath returns the path of the file in its partition.
+func (f *BackupFile) FilePath() string {
+	return pathpkg.Join("/", f.dir, f.Name)
+}
+
+// RestorePath returns the path of the file in the backup, as expected by RestoreFiles:
+// /<virtual disk>/<partition number>/<path in the partition>
+// Returns ErrNoClient for the files which were not listed from a backup.
+func (f *BackupFile) RestorePath() (string, error) {
+	if f.partition == nil || f.partition.disk == nil {
+		return "", ErrNoClient
+	}
+
+	return pathpkg.Join("/", f.partition.disk.Name, strconv.Itoa(f.partition.Number), f.FilePath()), nil
+}
+
+// RestoreFiles restores the files and folders of the backup into the VM.
+// The files must be listed from the backup, see VirtualDisks.
+func (b *Backup) RestoreFiles(vm *VirtualMachine, files []*BackupFile) error {
+	return b.RestoreFilesWithContext(context.Background(), vm, files)
+}
+
+// RestoreFilesWithContext is like RestoreFiles but uses ctx for the requests to the OVC.
+func (b *Backup) RestoreFilesWithContext(ctx context.Context, vm *VirtualMachine, files []*BackupFile) error {
+	task, err := b.RestoreFilesAsyncWithContext(ctx, vm, files)
+	if err != nil {
+		return err
+	}
+
+	return task.Wait(ctx)
+}
+
+// RestoreFilesAsync starts restoring the files and folders of the backup into the VM
+// and returns the task right away.
+func (b *Backup) RestoreFilesAsync(vm *VirtualMachine, files []*BackupFile) (*Task, error) {
+	return b.RestoreFilesAsyncWithContext(context.Background(), vm, files)
+}
+
+// RestoreFilesAsyncWithContext is like RestoreFilesAsync but uses ctx for the requests to the OVC.
+func (b *Backup) RestoreFilesAsyncWithContext(ctx context.Context, vm *VirtualMachine, files []*BackupFile) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	if vm == nil || len(files) == 0 {
+		return nil, errors.New("Pass a VM and the files to restore")
+	}
+
+	var (
+		path  = fmt.Sprintf("/backups/%s/restore_files", b.Id)
+		paths = make([]string, len(files))
+	)
+
+	for i, file := range files {
+		restorePath, err := file.RestorePath()
+		if err != nil {
+			return nil, err
+		}
+
+		// Files listed from another backup don't exist in this one
+		if file.partition.disk.backup == nil || file.partition.disk.backup.Id != b.Id {
+			return nil, fmt.Errorf("File %s was not listed from backup %s", restorePath, b.Id)
+		}
+
+		paths[i] = restorePath
+	}
+
+	body := map[string]interface{}{"virtual_machine_id": vm.Id,
+		"paths": paths}
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.RestoreFiles", b.Id)
+}
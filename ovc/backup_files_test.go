@@ -0,0 +1,188 @@
+package ovc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// mockBackupBrowse serves a backup with one disk, one partition,
+// a Users folder at its root and a file in the folder.
+func mockBackupBrowse(t *testing.T, apiHandler *http.ServeMux) {
+	apiHandler.HandleFunc("/backups/1/virtual_disks", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "GET")
+		fmt.Fprint(w, `{"virtual_disks": ["testvm.vmdk"]}`)
+	})
+
+	apiHandler.HandleFunc("/backups/1/virtual_disk_partitions", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "GET")
+		testFormValues(t, r, formValues{"virtual_disk": "testvm.vmdk"})
+		fmt.Fprint(w, `{"partitions": [{"partition_number": 2, "size": 1024, "file_system_type": "NTFS", "mountable": true}]}`)
+	})
+
+	apiHandler.HandleFunc("/backups/1/virtual_disk_partition_files", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "GET")
+		filePath := r.FormValue("file_path")
+		testFormValues(t, r, formValues{"virtual_disk": "testvm.vmdk", "partition_number": "2", "file_path": filePath})
+
+		switch filePath {
+		case "/":
+			fmt.Fprint(w, `{"virtual_disk_partition_files": [{"name": "Users", "directory": true}]}`)
+		case "/Users":
+			fmt.Fprint(w, `{"virtual_disk_partition_files": [{"name": "notes.txt", "size": 12}]}`)
+		default:
+			t.Errorf("Unexpected file path %s", filePath)
+		}
+	})
+}
+
+func TestBrowseBackupFiles(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupBrowse(t, apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	disks, err := backup.VirtualDisks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disks) != 1 || disks[0].Name != "testvm.vmdk" {
+		t.Fatalf("Virtual disks = %v, expected testvm.vmdk", disks)
+	}
+
+	partitions, err := disks[0].Partitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partitions) != 1 || partitions[0].Number != 2 || partitions[0].FileSystemType != "NTFS" {
+		t.Fatalf("Partitions = %v, expected the NTFS partition 2", partitions)
+	}
+
+	root, err := partitions[0].Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root) != 1 || !root[0].Directory {
+		t.Fatalf("Root files = %v, expected the Users folder", root)
+	}
+
+	files, err := root[0].Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "notes.txt" {
+		t.Fatalf("Files = %v, expected notes.txt", files)
+	}
+
+	if got := files[0].FilePath(); got != "/Users/notes.txt" {
+		t.Errorf("File path = %s, expected /Users/notes.txt", got)
+	}
+	if got, err := files[0].RestorePath(); err != nil || got != "/testvm.vmdk/2/Users/notes.txt" {
+		t.Errorf("Restore path = %s, %v, expected /testvm.vmdk/2/Users/notes.txt", got, err)
+	}
+
+	children, err := files[0].Files()
+	if err != nil || len(children) != 0 {
+		t.Errorf("Files of a file = %v, %v, expected none", children, err)
+	}
+}
+
+func TestRestoreFilesAsync(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupBrowse(t, apiHandler)
+
+	apiHandler.HandleFunc("/backups/1/restore_files", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Authorization", "Bearer 12345")
+		testRequestBody(t, r, `{"paths":["/testvm.vmdk/2/Users"],"virtual_machine_id":"2"}`+"\n")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	disks, err := backup.VirtualDisks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitions, err := disks[0].Partitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := partitions[0].Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := &VirtualMachine{Name: "testvm", Id: "2", client: client}
+	task, err := backup.RestoreFilesAsync(vm, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Done() {
+		t.Error("Task should be in progress")
+	}
+
+	err = task.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !task.Done() {
+		t.Error("Task should be done")
+	}
+}
+
+func TestRestoreFilesWithoutFiles(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	vm := &VirtualMachine{Name: "testvm", Id: "2", client: client}
+	_, err := backup.RestoreFilesAsync(vm, nil)
+	if err == nil {
+		t.Error("Restore without files should fail")
+	}
+}
+
+func TestRestoreFilesNotListedFromBackup(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupBrowse(t, apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	disks, err := backup.VirtualDisks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitions, err := disks[0].Partitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := partitions[0].Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := &VirtualMachine{Name: "testvm", Id: "2", client: client}
+
+	// A file built by the caller has no restore path
+	_, err = backup.RestoreFilesAsync(vm, []*BackupFile{{Name: "Users", Directory: true}})
+	if !errors.Is(err, ErrNoClient) {
+		t.Errorf("Restore of a file built by the caller returned %v, expected ErrNoClient", err)
+	}
+
+	// The files of backup 1 are not in backup 3
+	other := &Backup{Name: "otherbackup", Id: "3", client: client}
+	_, err = other.RestoreFilesAsync(vm, root)
+	if err == nil {
+		t.Error("Restore of the files of another backup should fail")
+	}
+}
@@ -1,10 +1,13 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -49,9 +52,114 @@ type Backup struct {
 	ComputeClusterParentName               string    `json:"compute_cluster_parent_name,omitempty"`
 	HypervisorType                         string    `json:"hypervisor_type,omitempty"`
 	SentDuration                           int       `json:"sent_duration,omitempty"`
+
+	// OVC client used for the backup operations
+	client *Client
+}
+
+// BackupState is the state of a backup.
+type BackupState string
+
+// Backup states
+const (
+	BackupStateProtected  BackupState = "PROTECTED"
+	BackupStateQueued     BackupState = "QUEUED"
+	BackupStateSaving     BackupState = "SAVING"
+	BackupStateFailed     BackupState = "FAILED"
+	BackupStateCanceled   BackupState = "CANCELED"
+	BackupStateCanceling  BackupState = "CANCELING"
+	BackupStateRebuilding BackupState = "REBUILDING"
+	BackupStateDegraded   BackupState = "DEGRADED"
+	BackupStateNew        BackupState = "NEW"
+	BackupStateUnknown    BackupState = "UNKNOWN"
+)
+
+// BackupType tells whether a backup was taken manually or by a policy.
+type BackupType string
+
+// Backup types
+const (
+	BackupTypeManual BackupType = "MANUAL"
+	BackupTypePolicy BackupType = "POLICY"
+)
+
+// BackupFilter is a typed filter of the backups.
+// See GetAll for the meaning of the fields.
+type BackupFilter struct {
+	Ids                                     []string
+	Names                                   []string
+	SentMin                                 int64
+	SentMax                                 int64
+	States                                  []BackupState
+	Types                                   []BackupType
+	OmniStackClusterIds                     []string
+	OmniStackClusterNames                   []string
+	ComputeClusterParentHypervisorObjectIds []string
+	ComputeClusterParentNames               []string
+	DatastoreIds                            []string
+	DatastoreNames                          []string
+	ExpiresBefore                           time.Time
+	ExpiresAfter                            time.Time
+	VirtualMachineIds                       []string
+	VirtualMachineNames                     []string
+	VirtualMachineTypes                     []string
+	SizeMin                                 int64
+	SizeMax                                 int64
+	ApplicationConsistent                   *bool
+	ConsistencyTypes                        []string
+	CreatedBefore                           time.Time
+	CreatedAfter                            time.Time
+	SentDurationMin                         time.Duration
+	SentDurationMax                         time.Duration
+	SentCompletionBefore                    time.Time
+	SentCompletionAfter                     time.Time
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f BackupFilter) Filters() map[string]string {
+	states := make([]string, len(f.States))
+	for i, state := range f.States {
+		states[i] = string(state)
+	}
+	types := make([]string, len(f.Types))
+	for i, backupType := range f.Types {
+		types[i] = string(backupType)
+	}
+
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.int("sent_min", f.SentMin)
+	values.int("sent_max", f.SentMax)
+	values.list("state", states)
+	values.list("type", types)
+	values.list("omnistack_cluster_id", f.OmniStackClusterIds)
+	values.list("omnistack_cluster_name", f.OmniStackClusterNames)
+	values.list("compute_cluster_parent_hypervisor_object_id", f.ComputeClusterParentHypervisorObjectIds)
+	values.list("compute_cluster_parent_name", f.ComputeClusterParentNames)
+	values.list("datastore_id", f.DatastoreIds)
+	values.list("datastore_name", f.DatastoreNames)
+	values.time("expires_before", f.ExpiresBefore)
+	values.time("expires_after", f.ExpiresAfter)
+	values.list("virtual_machine_id", f.VirtualMachineIds)
+	values.list("virtual_machine_name", f.VirtualMachineNames)
+	values.list("virtual_machine_type", f.VirtualMachineTypes)
+	values.int("size_min", f.SizeMin)
+	values.int("size_max", f.SizeMax)
+	values.bool("application_consistent", f.ApplicationConsistent)
+	values.list("consistency_type", f.ConsistencyTypes)
+	values.time("created_before", f.CreatedBefore)
+	values.time("created_after", f.CreatedAfter)
+	values.int("sent_duration_min", int64(f.SentDurationMin/time.Second))
+	values.int("sent_duration_max", int64(f.SentDurationMax/time.Second))
+	values.time("sent_completion_before", f.SentCompletionBefore)
+	values.time("sent_completion_after", f.SentCompletionAfter)
+
+	return values
 }
 
 // GetAll returns all the backups filtered by the query parameters.
+// Build the filters with BackupFilter.
 // Filters:
 //   id: the unique identifier (UID) of the backups to return
 //     Accepts: Single value, comma-separated list
@@ -112,6 +220,11 @@ type Backup struct {
 //   sent_completion_after: The earliest time after the replication of backups to return was
 //     completed, expressed in ISO-8601 form, based on Coordinated Universal Time (UTC)
 func (b *BackupResource) GetAll(params GetAllParams) (*BackupList, error) {
+	return b.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (b *BackupResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*BackupList, error) {
 	var (
 		path       = "/backups"
 		backupList BackupList
@@ -119,41 +232,124 @@ func (b *BackupResource) GetAll(params GetAllParams) (*BackupList, error) {
 
 	qrStr := params.QueryString()
 
-	resp, err := b.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := b.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &backupList, err
 	}
 
 	err = json.Unmarshal(resp, &backupList)
 	if err != nil {
-		log.Println(err)
 		return &backupList, err
 	}
 
+	for _, backup := range backupList.Members {
+		backup.client = b.client
+	}
+
 	return &backupList, nil
 }
 
+// GetAllPages returns all the backups matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of backups keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (b *BackupResource) GetAllPages(params GetAllParams) (*BackupList, error) {
+	return b.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (b *BackupResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*BackupList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*Backup
+	)
+
+	count, err := fetchAllPages(ctx, params, b.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		backupList, err := b.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*Backup{}
+		}
+		pages[page] = backupList.Members
+
+		return backupList.Count, len(backupList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backupList := &BackupList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		backupList.Members = append(backupList.Members, pages[page]...)
+	}
+
+	return backupList, nil
+}
+
+// Iterate calls fn with every backup matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (b *BackupResource) Iterate(params GetAllParams, fn func(backup *Backup) error) error {
+	return b.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (b *BackupResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(backup *Backup) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		backupList, err := b.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, backup := range backupList.Members {
+			err = fn(backup)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return backupList.Count, len(backupList.Members), nil
+	})
+}
+
 // GetBy searches for backups with single filter.
 func (b *BackupResource) GetBy(field string, value string) ([]*Backup, error) {
+	return b.GetByWithContext(context.Background(), field, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (b *BackupResource) GetByWithContext(ctx context.Context, field string, value string) ([]*Backup, error) {
+	var backups []*Backup
 	filters := map[string]string{field: value}
-	BackupList, err := b.GetAll(GetAllParams{Filters: filters})
+	err := b.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(backup *Backup) error {
+		backups = append(backups, backup)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	backups := BackupList.Members
-
 	return backups, nil
 }
 
 // GetByName searches for a backup by name.
 func (b *BackupResource) GetByName(name string) (*Backup, error) {
-	backups, err := b.GetBy("name", name)
+	return b.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (b *BackupResource) GetByNameWithContext(ctx context.Context, name string) (*Backup, error) {
+	backups, err := b.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -162,15 +358,19 @@ func (b *BackupResource) GetByName(name string) (*Backup, error) {
 		return backup, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a backup by id.
 func (b *BackupResource) GetById(id string) (*Backup, error) {
-	backups, err := b.GetBy("id", id)
+	return b.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (b *BackupResource) GetByIdWithContext(ctx context.Context, id string) (*Backup, error) {
+	backups, err := b.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -179,26 +379,858 @@ func (b *BackupResource) GetById(id string) (*Backup, error) {
 		return backup, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
+}
+
+// Number of backup ids in the filter of each request of getByIds
+const backupIdsPerRequest = 100
+
+// getByIds gets the backups with the ids, with one request per batch of ids.
+// Returns the backups by id, without the ones which don't exist.
+func (b *BackupResource) getByIds(ctx context.Context, ids []string) (map[string]*Backup, error) {
+	backups := make(map[string]*Backup, len(ids))
+	for start := 0; start < len(ids); start += backupIdsPerRequest {
+		end := start + backupIdsPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		params := GetAllParams{Filters: BackupFilter{Ids: ids[start:end]}.Filters()}
+		err := b.IterateWithContext(ctx, params, func(backup *Backup) error {
+			backups[backup.Id] = backup
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return backups, nil
 }
 
 // Delete deletes a backup.
 func (b *Backup) Delete() error {
-	var (
-		path = fmt.Sprintf("/backups/%s", b.Id)
-	)
+	return b.DeleteWithContext(context.Background())
+}
 
-	resp, err := commonClient.DoRequest("DELETE", path, "", nil, nil)
+// DeleteWithContext is like Delete but uses ctx for the requests to the OVC.
+func (b *Backup) DeleteWithContext(ctx context.Context) error {
+	task, err := b.DeleteAsyncWithContext(ctx)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
-	_, err = commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
 	return nil
 }
+
+// DeleteAsync starts deleting the backup and returns the task right away.
+func (b *Backup) DeleteAsync() (*Task, error) {
+	return b.DeleteAsyncWithContext(context.Background())
+}
+
+// DeleteAsyncWithContext is like DeleteAsync but uses ctx for the requests to the OVC.
+func (b *Backup) DeleteAsyncWithContext(ctx context.Context) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s", b.Id)
+	)
+
+	resp, err := b.client.DoRequestWithContext(ctx, "DELETE", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Delete", b.Id)
+}
+
+// Restore request of a backup to a new VM
+type RestoreRequest struct {
+	// The name of the new VM
+	VirtualMachineName string
+
+	// The datastore of the new VM
+	Datastore *Datastore
+}
+
+// Restore restores the backup and returns the restored VM.
+// Pass a nil request to restore the backup in place, over the original VM,
+// or else the name and the datastore of a new VM.
+func (b *Backup) Restore(req *RestoreRequest) (*VirtualMachine, error) {
+	return b.RestoreWithContext(context.Background(), req)
+}
+
+// RestoreWithContext is like Restore but uses ctx for the requests to the OVC.
+func (b *Backup) RestoreWithContext(ctx context.Context, req *RestoreRequest) (*VirtualMachine, error) {
+	task, err := b.RestoreAsyncWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := task.AffectedResources
+	if len(resources) < 1 {
+		return nil, newTaskError(task)
+	}
+
+	resource_id := resources[0].ObjectId
+	restoredVM, err := b.client.VirtualMachines.GetByIdWithContext(ctx, resource_id)
+
+	return restoredVM, err
+}
+
+// RestoreAsync starts restoring the backup and returns the task right away.
+// The id of the restored VM is the first affected object of the finished task.
+func (b *Backup) RestoreAsync(req *RestoreRequest) (*Task, error) {
+	return b.RestoreAsyncWithContext(context.Background(), req)
+}
+
+// RestoreAsyncWithContext is like RestoreAsync but uses ctx for the requests to the OVC.
+func (b *Backup) RestoreAsyncWithContext(ctx context.Context, req *RestoreRequest) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path     = fmt.Sprintf("/backups/%s/restore", b.Id)
+		queryStr = "restore_original=true"
+		body     interface{}
+	)
+
+	if req != nil {
+		if req.VirtualMachineName == "" || req.Datastore == nil {
+			return nil, errors.New("Pass the name and the datastore of the new VM")
+		}
+
+		queryStr = "restore_original=false"
+		body = map[string]interface{}{"virtual_machine_name": req.VirtualMachineName,
+			"datastore_id": req.Datastore.Id}
+	}
+
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, queryStr, body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Restore", b.Id)
+}
+
+// Copy copies the backup to another OmniStack cluster and returns the new remote backup.
+func (b *Backup) Copy(dest *OmniStackCluster) (*Backup, error) {
+	return b.CopyWithContext(context.Background(), dest)
+}
+
+// CopyWithContext is like Copy but uses ctx for the requests to the OVC.
+func (b *Backup) CopyWithContext(ctx context.Context, dest *OmniStackCluster) (*Backup, error) {
+	task, err := b.CopyAsyncWithContext(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.copiedBackup(ctx, task)
+}
+
+// CopyAsync starts copying the backup to another OmniStack cluster and returns the task right away.
+// The id of the new backup is the first affected object of the finished task.
+func (b *Backup) CopyAsync(dest *OmniStackCluster) (*Task, error) {
+	return b.CopyAsyncWithContext(context.Background(), dest)
+}
+
+// CopyAsyncWithContext is like CopyAsync but uses ctx for the requests to the OVC.
+func (b *Backup) CopyAsyncWithContext(ctx context.Context, dest *OmniStackCluster) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	if dest == nil {
+		return nil, errors.New("Pass the destination OmniStack cluster")
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s/copy", b.Id)
+	)
+
+	body := map[string]interface{}{"destination_id": dest.Id}
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Copy", b.Id)
+}
+
+// copiedBackup gets the new backup of a finished copy task.
+func (b *Backup) copiedBackup(ctx context.Context, task *Task) (*Backup, error) {
+	resource_id, err := copiedId(task)
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := b.client.Backups.GetByIdWithContext(ctx, resource_id)
+
+	return backup, err
+}
+
+// copiedId returns the id of the new backup of a finished copy task.
+func copiedId(task *Task) (string, error) {
+	resources := task.AffectedObjects()
+	if len(resources) < 1 {
+		return "", newTaskError(task)
+	}
+
+	return resources[0].ObjectId, nil
+}
+
+// CopyAll copies the backups to another OmniStack cluster, waits for all the copies
+// concurrently following opts and returns the new remote backups, in the order of backups.
+// Pass nil opts to poll the tasks at most 5 times per second in total.
+// The backups which failed to copy are nil in the result, and a *MultiError
+// holds their errors by backup id.
+func (b *BackupResource) CopyAll(backups []*Backup, dest *OmniStackCluster, opts *WaitAllOptions) ([]*Backup, error) {
+	return b.CopyAllWithContext(context.Background(), backups, dest, opts)
+}
+
+// CopyAllWithContext is like CopyAll but uses ctx for the requests to the OVC.
+func (b *BackupResource) CopyAllWithContext(ctx context.Context, backups []*Backup, dest *OmniStackCluster, opts *WaitAllOptions) ([]*Backup, error) {
+	tasks, errs := b.runTasks(ctx, backups, opts, func(backup *Backup) (*Task, error) {
+		return backup.CopyAsyncWithContext(ctx, dest)
+	})
+
+	var (
+		copies    = make([]*Backup, len(backups))
+		copiedIds = make([]string, len(backups))
+		ids       []string
+	)
+
+	for i, task := range tasks {
+		if task == nil {
+			continue
+		}
+
+		id, err := copiedId(task)
+		if err != nil {
+			errs[backups[i].Id] = err
+			continue
+		}
+
+		copiedIds[i] = id
+		ids = append(ids, id)
+	}
+
+	found, err := b.getByIds(ctx, ids)
+	for i, id := range copiedIds {
+		switch {
+		case id == "":
+		case err != nil:
+			errs[backups[i].Id] = err
+		case found[id] == nil:
+			errs[backups[i].Id] = ErrNotFound
+		default:
+			copies[i] = found[id]
+		}
+	}
+
+	if len(errs) > 0 {
+		return copies, &MultiError{Errors: errs}
+	}
+
+	return copies, nil
+}
+
+// Maximum number of polls per second of the tasks of the bulk backup
+// operations, when the caller passes no WaitAllOptions
+const defaultBulkPollsPerSecond = 5
+
+// runTasks starts a task for each of the backups with start, and waits for all the
+// tasks concurrently following opts, or else polling at most 5 times per second.
+// Returns the task of each backup, nil for the backups whose task failed,
+// and the errors of the failed backups by backup id.
+func (b *BackupResource) runTasks(ctx context.Context, backups []*Backup, opts *WaitAllOptions,
+	start func(backup *Backup) (*Task, error)) ([]*Task, map[string]error) {
+	if opts == nil {
+		opts = &WaitAllOptions{PollsPerSecond: defaultBulkPollsPerSecond}
+	}
+
+	var (
+		tasks   = make([]*Task, len(backups))
+		started []*Task
+		errs    = map[string]error{}
+	)
+
+	for i, backup := range backups {
+		task, err := start(backup)
+		if err != nil {
+			errs[backup.Id] = err
+			continue
+		}
+
+		tasks[i] = task
+		started = append(started, task)
+	}
+
+	results, _ := b.client.Tasks.WaitAll(ctx, started, opts)
+
+	for i, backup := range backups {
+		if tasks[i] == nil {
+			continue
+		}
+
+		err := results[tasks[i].Id].Err
+		if err != nil {
+			errs[backup.Id] = err
+			tasks[i] = nil
+		}
+	}
+
+	return tasks, errs
+}
+
+// refresh re-reads the backup with the client after a finished task and updates its fields.
+// The backup is bound to the client on the way.
+// Returns a *TaskError if the task does not report the backup as affected.
+func (b *Backup) refresh(ctx context.Context, client *Client, task *Task) (*Backup, error) {
+	if !task.affects(b.Id) {
+		return nil, newTaskError(task)
+	}
+
+	updated, err := client.Backups.GetByIdWithContext(ctx, b.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	*b = *updated
+	return b, nil
+}
+
+// reread re-reads the backups with the client after finished tasks
+// and updates their fields, with one request per batch of backups.
+// The backups are bound to the client on the way.
+// Returns the errors of the backups which could not be re-read, by backup id.
+func reread(ctx context.Context, client *Client, backups []*Backup) map[string]error {
+	ids := make([]string, len(backups))
+	for i, backup := range backups {
+		ids[i] = backup.Id
+	}
+
+	errs := map[string]error{}
+	found, err := client.Backups.getByIds(ctx, ids)
+	for _, backup := range backups {
+		switch {
+		case err != nil:
+			errs[backup.Id] = err
+		case found[backup.Id] == nil:
+			errs[backup.Id] = ErrNotFound
+		default:
+			*backup = *found[backup.Id]
+		}
+	}
+
+	return errs
+}
+
+// Lock locks the backup, so that the retention of its policy never expires it.
+// Returns the backup with the updated fields.
+func (b *Backup) Lock() (*Backup, error) {
+	return b.LockWithContext(context.Background())
+}
+
+// LockWithContext is like Lock but uses ctx for the requests to the OVC.
+func (b *Backup) LockWithContext(ctx context.Context) (*Backup, error) {
+	task, err := b.LockAsyncWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.refresh(ctx, b.client, task)
+}
+
+// LockAsync starts locking the backup and returns the task right away.
+func (b *Backup) LockAsync() (*Task, error) {
+	return b.LockAsyncWithContext(context.Background())
+}
+
+// LockAsyncWithContext is like LockAsync but uses ctx for the requests to the OVC.
+func (b *Backup) LockAsyncWithContext(ctx context.Context) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s/lock", b.Id)
+	)
+
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Lock", b.Id)
+}
+
+// Rename renames the backup.
+// Returns the backup with the updated fields.
+func (b *Backup) Rename(name string) (*Backup, error) {
+	return b.RenameWithContext(context.Background(), name)
+}
+
+// RenameWithContext is like Rename but uses ctx for the requests to the OVC.
+func (b *Backup) RenameWithContext(ctx context.Context, name string) (*Backup, error) {
+	task, err := b.RenameAsyncWithContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.refresh(ctx, b.client, task)
+}
+
+// RenameAsync starts renaming the backup and returns the task right away.
+func (b *Backup) RenameAsync(name string) (*Task, error) {
+	return b.RenameAsyncWithContext(context.Background(), name)
+}
+
+// RenameAsyncWithContext is like RenameAsync but uses ctx for the requests to the OVC.
+func (b *Backup) RenameAsyncWithContext(ctx context.Context, name string) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	if name == "" {
+		return nil, errors.New("Pass a new name of the backup")
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s/rename", b.Id)
+	)
+
+	body := map[string]interface{}{"backup_name": name}
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Rename", b.Id)
+}
+
+// Cancel cancels the replication of a remote backup which is still SAVING.
+// Returns the backup with the updated fields.
+func (b *Backup) Cancel() (*Backup, error) {
+	return b.CancelWithContext(context.Background())
+}
+
+// CancelWithContext is like Cancel but uses ctx for the requests to the OVC.
+func (b *Backup) CancelWithContext(ctx context.Context) (*Backup, error) {
+	task, err := b.CancelAsyncWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.refresh(ctx, b.client, task)
+}
+
+// CancelAsync starts cancelling the replication of the backup and returns the task right away.
+func (b *Backup) CancelAsync() (*Task, error) {
+	return b.CancelAsyncWithContext(context.Background())
+}
+
+// CancelAsyncWithContext is like CancelAsync but uses ctx for the requests to the OVC.
+func (b *Backup) CancelAsyncWithContext(ctx context.Context) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	if b.State != string(BackupStateSaving) {
+		return nil, fmt.Errorf("Backup %s is %s, only %s backups can be canceled", b.Id, b.State, BackupStateSaving)
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s/cancel_replication", b.Id)
+	)
+
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.Cancel", b.Id)
+}
+
+// SetRetention sets the retention of the backups, in minutes, in a single call.
+// Pass force to allow a retention which expires some of the backups right away.
+// Returns the backups with the updated fields, and a *MultiError holding by backup id
+// the errors of the backups the task does not report as affected, or which could
+// not be re-read.
+func (b *BackupResource) SetRetention(backups []*Backup, retention int, force bool) ([]*Backup, error) {
+	return b.SetRetentionWithContext(context.Background(), backups, retention, force)
+}
+
+// SetRetentionWithContext is like SetRetention but uses ctx for the requests to the OVC.
+func (b *BackupResource) SetRetentionWithContext(ctx context.Context, backups []*Backup, retention int, force bool) ([]*Backup, error) {
+	task, err := b.SetRetentionAsyncWithContext(ctx, backups, retention, force)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		updated []*Backup
+		errs    = map[string]error{}
+	)
+
+	for _, backup := range backups {
+		if !task.affects(backup.Id) {
+			errs[backup.Id] = newTaskError(task)
+			continue
+		}
+
+		updated = append(updated, backup)
+	}
+
+	for id, err := range reread(ctx, b.client, updated) {
+		errs[id] = err
+	}
+
+	if len(errs) > 0 {
+		return backups, &MultiError{Errors: errs}
+	}
+
+	return backups, nil
+}
+
+// SetRetentionAsync starts setting the retention of the backups and returns the task right away.
+func (b *BackupResource) SetRetentionAsync(backups []*Backup, retention int, force bool) (*Task, error) {
+	return b.SetRetentionAsyncWithContext(context.Background(), backups, retention, force)
+}
+
+// SetRetentionAsyncWithContext is like SetRetentionAsync but uses ctx for the requests to the OVC.
+func (b *BackupResource) SetRetentionAsyncWithContext(ctx context.Context, backups []*Backup, retention int, force bool) (*Task, error) {
+	if len(backups) == 0 {
+		return nil, errors.New("Pass a list of backup resources")
+	}
+
+	var (
+		path       = "/backups/set_retention"
+		backup_ids = make([]string, len(backups))
+	)
+
+	for i, backup := range backups {
+		backup_ids[i] = backup.Id
+	}
+
+	body := map[string]interface{}{"backup_id": backup_ids,
+		"retention": retention,
+		"force":     force}
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backups.SetRetention", "")
+}
+
+// Number of backups deleted by each request of DeleteAll, and number of requests
+// running at the same time
+const (
+	defaultDeleteBatchSize = 100
+	defaultDeleteWorkers   = 4
+)
+
+// DeleteAllOptions controls how DeleteAll splits the deletion of the backups.
+type DeleteAllOptions struct {
+	// Maximum number of backups deleted by each request, 0 for the default of 100
+	BatchSize int
+
+	// Maximum number of batches deleted at the same time, 0 for the default of 4
+	Workers int
+}
+
+// ErrBackupNotDeleted is returned for the backups which the delete task
+// completed without reporting as deleted.
+var ErrBackupNotDeleted = errors.New("Backup not reported as deleted by the task")
+
+// DeleteAllResult is the outcome of DeleteAll.
+type DeleteAllResult struct {
+	// Ids of the deleted backups, in the order of the backups
+	Deleted []string
+
+	// Errors of the backups which failed to delete, by backup id
+	Failed map[string]error
+
+	// Errors of the backups whose deletion was requested but not confirmed,
+	// because the request or the wait for the task was cut short by the context,
+	// the connection or the timeout of the poll policy. The OVC may still delete them.
+	Unknown map[string]error
+}
+
+// DeleteAll deletes the backups in batches through the multi-delete endpoint,
+// running the batches concurrently following the options.
+// A backup is deleted only when the task of its batch reports it as affected.
+// Returns which backups were deleted, failed or are unknown, with a *MultiError
+// holding the errors of the failed and unknown ones by backup id.
+func (b *BackupResource) DeleteAll(backups []*Backup, opts *DeleteAllOptions) (*DeleteAllResult, error) {
+	return b.DeleteAllWithContext(context.Background(), backups, opts)
+}
+
+// DeleteAllWithContext is like DeleteAll but uses ctx for the requests to the OVC.
+func (b *BackupResource) DeleteAllWithContext(ctx context.Context, backups []*Backup, opts *DeleteAllOptions) (*DeleteAllResult, error) {
+	if opts == nil {
+		opts = &DeleteAllOptions{}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultDeleteBatchSize
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = defaultDeleteWorkers
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		sem     = make(chan struct{}, workers)
+		failed  = map[string]error{}
+		unknown = map[string]error{}
+	)
+
+	for start := 0; start < len(backups); start += batchSize {
+		end := start + batchSize
+		if end > len(backups) {
+			end = len(backups)
+		}
+
+		wg.Add(1)
+		go func(batch []*Backup) {
+			defer wg.Done()
+
+			var batchFailed, batchUnknown map[string]error
+			select {
+			case <-ctx.Done():
+				batchFailed = batchErrors(batch, nil, ctx.Err())
+			case sem <- struct{}{}:
+				batchFailed, batchUnknown = b.deleteBatch(ctx, batch)
+				<-sem
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for id, err := range batchFailed {
+				failed[id] = err
+			}
+			for id, err := range batchUnknown {
+				unknown[id] = err
+			}
+		}(backups[start:end])
+	}
+	wg.Wait()
+
+	result := &DeleteAllResult{Deleted: []string{}, Failed: failed, Unknown: unknown}
+	errs := make(map[string]error, len(failed)+len(unknown))
+	for _, backup := range backups {
+		if err, ok := failed[backup.Id]; ok {
+			errs[backup.Id] = err
+		} else if err, ok := unknown[backup.Id]; ok {
+			errs[backup.Id] = err
+		} else {
+			result.Deleted = append(result.Deleted, backup.Id)
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, &MultiError{Errors: errs}
+	}
+
+	return result, nil
+}
+
+// deleteBatch deletes the batch of backups with a single request,
+// and returns the errors of the backups which were not deleted, and of
+// the backups which are unknown because the request or the wait for the task
+// was cut short after the OVC may have received the request.
+func (b *BackupResource) deleteBatch(ctx context.Context, batch []*Backup) (failed, unknown map[string]error) {
+	backup_ids := make([]string, len(batch))
+	for i, backup := range batch {
+		backup_ids[i] = backup.Id
+	}
+
+	// Log in first, so that the trace below only sees the delete request
+	_, err := b.client.accessToken(ctx)
+	if err != nil {
+		return batchErrors(batch, nil, err), nil
+	}
+
+	var sent int32
+	traceCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				atomic.StoreInt32(&sent, 1)
+			}
+		},
+	})
+
+	body := map[string]interface{}{"backup_id": backup_ids}
+	resp, err := b.client.DoRequestWithContext(traceCtx, "POST", "/backups/delete", "", body, nil)
+	if err != nil {
+		// The OVC may have received the request and started the deletion,
+		// unless it rejected it.
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) && (ctx.Err() != nil || atomic.LoadInt32(&sent) == 1) {
+			return nil, batchErrors(batch, nil, err)
+		}
+		return batchErrors(batch, nil, err), nil
+	}
+
+	// The OVC accepted the request, the deletion may be running
+	task, err := b.client.startTask(resp, "Backups.DeleteAll", "")
+	if err != nil {
+		return nil, batchErrors(batch, nil, err)
+	}
+
+	// Only the backups the task reports as affected were deleted,
+	// whether the task completed or failed.
+	err = task.Wait(ctx)
+	switch {
+	case err == nil:
+		return batchErrors(batch, task.AffectedObjects(), ErrBackupNotDeleted), nil
+	case ctx.Err() != nil || errors.Is(err, ErrTaskTimeout):
+		return nil, batchErrors(batch, task.AffectedObjects(), err)
+	default:
+		return batchErrors(batch, task.AffectedObjects(), err), nil
+	}
+}
+
+// batchErrors returns err for each backup of the batch
+// which is not one of the deleted objects.
+func batchErrors(batch []*Backup, deleted []*AffectedResource, err error) map[string]error {
+	errs := make(map[string]error, len(batch))
+	for _, backup := range batch {
+		errs[backup.Id] = err
+	}
+
+	for _, object := range deleted {
+		delete(errs, object.ObjectId)
+	}
+
+	return errs
+}
+
+// CalculateUniqueSize calculates the unique size of the backup, which the OVC
+// otherwise calculates lazily.
+// Returns the backup with the updated UniqueSizeBytes and UniqueSizeTimestamp.
+func (b *Backup) CalculateUniqueSize() (*Backup, error) {
+	return b.CalculateUniqueSizeWithContext(context.Background())
+}
+
+// CalculateUniqueSizeWithContext is like CalculateUniqueSize but uses ctx for the requests to the OVC.
+func (b *Backup) CalculateUniqueSizeWithContext(ctx context.Context) (*Backup, error) {
+	task, err := b.CalculateUniqueSizeAsyncWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.refresh(ctx, b.client, task)
+}
+
+// CalculateUniqueSizeAsync starts calculating the unique size of the backup
+// and returns the task right away.
+func (b *Backup) CalculateUniqueSizeAsync() (*Task, error) {
+	return b.CalculateUniqueSizeAsyncWithContext(context.Background())
+}
+
+// CalculateUniqueSizeAsyncWithContext is like CalculateUniqueSizeAsync but uses ctx for the requests to the OVC.
+func (b *Backup) CalculateUniqueSizeAsyncWithContext(ctx context.Context) (*Task, error) {
+	if b.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/backups/%s/calculate_unique_size", b.Id)
+	)
+
+	resp, err := b.client.DoRequestWithContext(ctx, "POST", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.startTask(resp, "Backup.CalculateUniqueSize", b.Id)
+}
+
+// CalculateUniqueSizes calculates the unique size of the backups, waits for all the
+// calculations concurrently following opts and returns the backups with the updated sizes.
+// Pass nil opts to poll the tasks at most 5 times per second in total.
+// Returns a *MultiError holding the errors of the backups which failed, by backup id.
+func (b *BackupResource) CalculateUniqueSizes(backups []*Backup, opts *WaitAllOptions) ([]*Backup, error) {
+	return b.CalculateUniqueSizesWithContext(context.Background(), backups, opts)
+}
+
+// CalculateUniqueSizesWithContext is like CalculateUniqueSizes but uses ctx for the requests to the OVC.
+func (b *BackupResource) CalculateUniqueSizesWithContext(ctx context.Context, backups []*Backup, opts *WaitAllOptions) ([]*Backup, error) {
+	tasks, errs := b.runTasks(ctx, backups, opts, func(backup *Backup) (*Task, error) {
+		return backup.CalculateUniqueSizeAsyncWithContext(ctx)
+	})
+
+	var calculated []*Backup
+	for i, task := range tasks {
+		if task == nil {
+			continue
+		}
+
+		if !task.affects(backups[i].Id) {
+			errs[backups[i].Id] = newTaskError(task)
+			continue
+		}
+
+		calculated = append(calculated, backups[i])
+	}
+
+	for id, err := range reread(ctx, b.client, calculated) {
+		errs[id] = err
+	}
+
+	if len(errs) > 0 {
+		return backups, &MultiError{Errors: errs}
+	}
+
+	return backups, nil
+}
@@ -1,10 +1,17 @@
 package ovc
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBackupGetAllWithDefaultParameters(t *testing.T) {
@@ -28,7 +35,7 @@ func TestBackupGetAllWithDefaultParameters(t *testing.T) {
 		t.Error(err)
 	}
 
-	resource := &Backup{Name: "test", Id: "1"}
+	resource := &Backup{Name: "test", Id: "1", client: client}
 	expected := &BackupList{Offset: 1,
 		Limit:   1,
 		Count:   1,
@@ -123,3 +130,574 @@ func TestBackupGetById(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRestoreInPlace(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	//Mock get by id request
+	mockGetVMById(apiHandler)
+
+	apiHandler.HandleFunc("/backups/1/restore", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Authorization", "Bearer 12345")
+		testFormValues(t, r, formValues{"restore_original": "true"})
+		testRequestBody(t, r, "")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	vm, err := backup.Restore(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vm.Id != "1" {
+		t.Errorf("Restored VM id = %s, expected 1", vm.Id)
+	}
+}
+
+func TestRestoreToNewVM(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	//Mock get by id request
+	mockGetVMById(apiHandler)
+
+	apiHandler.HandleFunc("/backups/1/restore", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testFormValues(t, r, formValues{"restore_original": "false"})
+		testRequestBody(t, r, `{"datastore_id":"2","virtual_machine_name":"testvm"}`+"\n")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	req := &RestoreRequest{VirtualMachineName: "testvm", Datastore: &Datastore{Name: "testds", Id: "2"}}
+	vm, err := backup.Restore(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vm.Name != "testvm" {
+		t.Errorf("Restored VM name = %s, expected testvm", vm.Name)
+	}
+}
+
+func TestRestoreToNewVMWithoutDatastore(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	_, err := backup.Restore(&RestoreRequest{VirtualMachineName: "testvm"})
+	if err == nil {
+		t.Error("Restore without a datastore should fail")
+	}
+}
+
+// mockGetBackupsById serves the backups by id, named after their id.
+// Returns the id filters of the requests.
+func mockGetBackupsById(apiHandler *http.ServeMux) *[]string {
+	var mu sync.Mutex
+	filters := []string{}
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("id")
+		mu.Lock()
+		filters = append(filters, filter)
+		mu.Unlock()
+
+		members := []string{}
+		for _, id := range strings.Split(filter, ",") {
+			members = append(members, fmt.Sprintf(`{"name": "backup%s", "id":"%s"}`, id, id))
+		}
+		fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": %d, "backups":[%s]}`, len(members), strings.Join(members, ","))
+	})
+
+	return &filters
+}
+
+func TestCopy(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockGetBackupsById(apiHandler)
+
+	apiHandler.HandleFunc("/backups/2/copy", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Authorization", "Bearer 12345")
+		testRequestBody(t, r, `{"destination_id":"3"}`+"\n")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	backup := &Backup{Name: "testbackup", Id: "2", client: client}
+	copied, err := backup.Copy(&OmniStackCluster{Name: "dr", Id: "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if copied.Id != "1" {
+		t.Errorf("Copied backup id = %s, expected 1", copied.Id)
+	}
+}
+
+func TestCopyAll(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	filters := mockGetBackupsById(apiHandler)
+
+	for _, id := range []string{"1", "2", "3"} {
+		id := id
+		apiHandler.HandleFunc("/backups/"+id+"/copy", func(w http.ResponseWriter, r *http.Request) {
+			testRequestMethod(t, r, "POST")
+			if id == "3" {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"message": "Backup is already copied"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"task":{"state": "IN_PROGRESS", "id": "task%s", "percent_complete": 1, "affected_objects":[]}}`, id)
+		})
+		apiHandler.HandleFunc("/tasks/task"+id, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"task": {"state": "COMPLETE", "id": "task%s", "percent_complete": 100, "affected_objects":[{"object_type":"backup", "object_id":"1%s"}]}}`, id, id)
+		})
+	}
+
+	var backups []*Backup
+	for _, id := range []string{"1", "2", "3"} {
+		backups = append(backups, &Backup{Id: id, client: client})
+	}
+
+	copies, err := client.Backups.CopyAll(backups, &OmniStackCluster{Name: "dr", Id: "3"}, nil)
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Returned %v, expected a MultiError", err)
+	}
+
+	if len(multiErr.Errors) != 1 || !errors.Is(multiErr.Errors["3"], ErrConflict) {
+		t.Errorf("Errors = %v, expected a conflict of backup 3", multiErr.Errors)
+	}
+
+	if len(copies) != 3 || copies[0].Id != "11" || copies[1].Id != "12" || copies[2] != nil {
+		t.Errorf("Copies = %v, expected the backups 11 and 12", copies)
+	}
+
+	// The copies are read in a single request
+	if !reflect.DeepEqual(*filters, []string{"11,12"}) {
+		t.Errorf("Requested the backups %v, expected 11,12 at once", *filters)
+	}
+}
+
+// mockBackupUpdate serves the backup 1 with the fields in backupJSON,
+// and the task endpoint.
+func mockBackupUpdate(apiHandler *http.ServeMux, backupJSON string) {
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": 1, "backups":[%s]}`, backupJSON)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+}
+
+func TestLock(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupUpdate(apiHandler, `{"name": "testbackup", "id": "1", "expiration_time": "NA"}`)
+
+	apiHandler.HandleFunc("/backups/1/lock", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Authorization", "Bearer 12345")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	backup := &Backup{Name: "testbackup", Id: "1", ExpirationTime: "2020-01-01T00:00:00Z", client: client}
+	locked, err := backup.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if locked != backup || backup.ExpirationTime != "NA" {
+		t.Errorf("Expiration time = %s, expected NA", backup.ExpirationTime)
+	}
+}
+
+func TestRename(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupUpdate(apiHandler, `{"name": "newname", "id": "1"}`)
+
+	apiHandler.HandleFunc("/backups/1/rename", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestBody(t, r, `{"backup_name":"newname"}`+"\n")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
+	renamed, err := backup.Rename("newname")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renamed.Name != "newname" {
+		t.Errorf("Backup name = %s, expected newname", renamed.Name)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockBackupUpdate(apiHandler, `{"name": "testbackup", "id": "1", "state": "CANCELED"}`)
+
+	apiHandler.HandleFunc("/backups/1/cancel_replication", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	backup := &Backup{Name: "testbackup", Id: "1", State: "SAVING", client: client}
+	canceled, err := backup.Cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if canceled.State != string(BackupStateCanceled) {
+		t.Errorf("Backup state = %s, expected CANCELED", canceled.State)
+	}
+
+	// The backup is no longer saving
+	_, err = backup.Cancel()
+	if err == nil {
+		t.Error("Cancel of a canceled backup should fail")
+	}
+}
+
+// mockSetRetention serves the backups with the new expiration time,
+// and a finished task reporting the backups with the ids as affected.
+// Returns the number of requests re-reading the backups.
+func mockSetRetention(t *testing.T, apiHandler *http.ServeMux, ids ...string) *int32 {
+	var requests int32
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		members := []string{}
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			members = append(members, fmt.Sprintf(`{"id": "%s", "expiration_time": "2030-01-01T00:00:00Z"}`, id))
+		}
+		fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": %d, "backups":[%s]}`, len(members), strings.Join(members, ","))
+	})
+
+	apiHandler.HandleFunc("/backups/set_retention", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestBody(t, r, `{"backup_id":["1","2"],"force":true,"retention":60}`+"\n")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	objects := make([]string, len(ids))
+	for i, id := range ids {
+		objects[i] = fmt.Sprintf(`{"object_type":"backup", "object_id":"%s"}`, id)
+	}
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"task": {"state": "COMPLETE", "id": "1", "percent_complete": 100, "affected_objects":[%s]}}`, strings.Join(objects, ","))
+	})
+
+	return &requests
+}
+
+func TestSetRetention(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	requests := mockSetRetention(t, apiHandler, "1", "2")
+
+	// Backups built by the caller are not bound to a client
+	backups := []*Backup{{Id: "1"}, {Id: "2"}}
+	updated, err := client.Backups.SetRetention(backups, 60, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backup := range updated {
+		if backup.ExpirationTime != "2030-01-01T00:00:00Z" {
+			t.Errorf("Expiration time of backup %s = %s, expected 2030-01-01T00:00:00Z", backup.Id, backup.ExpirationTime)
+		}
+		if backup.client != client {
+			t.Errorf("Backup %s is not bound to the client", backup.Id)
+		}
+	}
+
+	if *requests != 1 {
+		t.Errorf("Re-read the backups with %d requests, expected 1", *requests)
+	}
+}
+
+func TestSetRetentionNotAffected(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// The task skips the backup 2
+	mockSetRetention(t, apiHandler, "1")
+
+	backups := []*Backup{{Id: "1"}, {Id: "2"}}
+	updated, err := client.Backups.SetRetention(backups, 60, true)
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 1 || !errors.Is(multiErr.Errors["2"], ErrTaskFailed) {
+		t.Fatalf("Returned %v, expected a task error for backup 2", err)
+	}
+
+	if updated[0].ExpirationTime != "2030-01-01T00:00:00Z" || updated[1].ExpirationTime != "" {
+		t.Errorf("Expiration times = %s, %s, expected backup 1 only updated", updated[0].ExpirationTime, updated[1].ExpirationTime)
+	}
+}
+
+func TestDeleteAll(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	var (
+		mu               sync.Mutex
+		running, maxRuns int
+	)
+	apiHandler.HandleFunc("/backups/delete", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+
+		var body struct {
+			BackupIds []string `json:"backup_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		running++
+		if running > maxRuns {
+			maxRuns = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if body.BackupIds[0] == "5" {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message": "Backup is locked"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"task":{"state": "IN_PROGRESS", "id": "task%s", "percent_complete": 1, "affected_objects":[]}}`, body.BackupIds[0])
+	})
+
+	apiHandler.HandleFunc("/tasks/task1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "COMPLETE", "id": "task1", "percent_complete": 100, "affected_objects":[{"object_id":"1"}, {"object_id":"2"}]}}`)
+	})
+
+	// Only the backup 4 of the second batch is deleted
+	apiHandler.HandleFunc("/tasks/task3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "FAILED", "id": "task3", "percent_complete": 100, "error_code": 1, "affected_objects":[{"object_id":"4"}]}}`)
+	})
+
+	var backups []*Backup
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		backups = append(backups, &Backup{Id: id, client: client})
+	}
+
+	result, err := client.Backups.DeleteAll(backups, &DeleteAllOptions{BatchSize: 2, Workers: 2})
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Returned %v, expected a MultiError", err)
+	}
+
+	if !reflect.DeepEqual(result.Deleted, []string{"1", "2", "4"}) {
+		t.Errorf("Deleted = %v, expected 1, 2 and 4", result.Deleted)
+	}
+
+	if len(result.Failed) != 2 || !errors.Is(result.Failed["3"], ErrTaskFailed) || !errors.Is(result.Failed["5"], ErrConflict) {
+		t.Errorf("Failed = %v, expected a failed task for 3 and a conflict for 5", result.Failed)
+	}
+
+	if maxRuns > 2 {
+		t.Errorf("%d batches were deleted at the same time, expected at most 2", maxRuns)
+	}
+}
+
+func TestDeleteAllUnconfirmed(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/backups/delete", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			BackupIds []string `json:"backup_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		fmt.Fprintf(w, `{"task":{"state": "IN_PROGRESS", "id": "task%s", "percent_complete": 1, "affected_objects":[]}}`, body.BackupIds[0])
+	})
+
+	// The first batch completes without reporting the backup 2 as deleted
+	apiHandler.HandleFunc("/tasks/task1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "COMPLETE", "id": "task1", "percent_complete": 100, "affected_objects":[{"object_id":"1"}]}}`)
+	})
+
+	// The second batch is still running when the wait times out
+	apiHandler.HandleFunc("/tasks/task3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "IN_PROGRESS", "id": "task3", "percent_complete": 50, "affected_objects":[]}}`)
+	})
+
+	var backups []*Backup
+	for _, id := range []string{"1", "2", "3", "4"} {
+		backups = append(backups, &Backup{Id: id, client: client})
+	}
+
+	ctx := ContextWithPollPolicy(context.Background(), &PollPolicy{InitialInterval: 5 * time.Millisecond, Timeout: 50 * time.Millisecond})
+	result, err := client.Backups.DeleteAllWithContext(ctx, backups, &DeleteAllOptions{BatchSize: 2})
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 3 {
+		t.Fatalf("Returned %v, expected a MultiError for 2, 3 and 4", err)
+	}
+
+	if !reflect.DeepEqual(result.Deleted, []string{"1"}) {
+		t.Errorf("Deleted = %v, expected 1", result.Deleted)
+	}
+
+	if len(result.Failed) != 1 || !errors.Is(result.Failed["2"], ErrBackupNotDeleted) {
+		t.Errorf("Failed = %v, expected 2 not deleted", result.Failed)
+	}
+
+	if len(result.Unknown) != 2 || !errors.Is(result.Unknown["3"], ErrTaskTimeout) || !errors.Is(result.Unknown["4"], ErrTaskTimeout) {
+		t.Errorf("Unknown = %v, expected a timeout for 3 and 4", result.Unknown)
+	}
+}
+
+func TestDeleteAllRequestCutShort(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// The OVC receives the request but responds after the deadline
+	received := make(chan struct{}, 1)
+	apiHandler.HandleFunc("/backups/delete", func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	backups := []*Backup{{Id: "1", client: client}, {Id: "2", client: client}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result, err := client.Backups.DeleteAllWithContext(ctx, backups, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Returned %v, expected the deadline of the context", err)
+	}
+
+	select {
+	case <-received:
+	default:
+		t.Fatal("The OVC did not receive the request")
+	}
+
+	if len(result.Deleted) != 0 || len(result.Failed) != 0 {
+		t.Errorf("Deleted = %v, Failed = %v, expected none", result.Deleted, result.Failed)
+	}
+	if len(result.Unknown) != 2 || !errors.Is(result.Unknown["1"], context.DeadlineExceeded) {
+		t.Errorf("Unknown = %v, expected 1 and 2 cut short by the deadline", result.Unknown)
+	}
+}
+
+func TestCalculateUniqueSizes(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	var requests int32
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		members := []string{}
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			members = append(members, fmt.Sprintf(`{"id": "%s", "unique_size_bytes": %s000, "unique_size_timestamp": "2026-01-01T00:00:00Z"}`, id, id))
+		}
+		fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": %d, "backups":[%s]}`, len(members), strings.Join(members, ","))
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		id := id
+		apiHandler.HandleFunc("/backups/"+id+"/calculate_unique_size", func(w http.ResponseWriter, r *http.Request) {
+			testRequestMethod(t, r, "POST")
+			testRequestHeader(t, r, "Authorization", "Bearer 12345")
+			fmt.Fprintf(w, `{"task":{"state": "IN_PROGRESS", "id": "task%s", "percent_complete": 1, "affected_objects":[]}}`, id)
+		})
+		apiHandler.HandleFunc("/tasks/task"+id, func(w http.ResponseWriter, r *http.Request) {
+			state := "COMPLETE"
+			if id == "3" {
+				state = "FAILED"
+			}
+			fmt.Fprintf(w, `{"task": {"state": "%s", "id": "task%s", "percent_complete": 100, "affected_objects":[{"object_type":"backup", "object_id":"%s"}]}}`, state, id, id)
+		})
+	}
+
+	var backups []*Backup
+	for _, id := range []string{"1", "2", "3"} {
+		backups = append(backups, &Backup{Id: id, client: client})
+	}
+
+	updated, err := client.Backups.CalculateUniqueSizes(backups, &WaitAllOptions{PollsPerSecond: 20})
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Returned %v, expected a MultiError", err)
+	}
+
+	if len(multiErr.Errors) != 1 || !errors.Is(multiErr.Errors["3"], ErrTaskFailed) {
+		t.Errorf("Errors = %v, expected a failed task of backup 3", multiErr.Errors)
+	}
+
+	if updated[0].UniqueSizeBytes != 1000 || updated[1].UniqueSizeBytes != 2000 || updated[2].UniqueSizeBytes != 0 {
+		t.Errorf("Unique sizes = %d, %d, %d, expected 1000, 2000 and 0",
+			updated[0].UniqueSizeBytes, updated[1].UniqueSizeBytes, updated[2].UniqueSizeBytes)
+	}
+
+	if updated[0].UniqueSizeTimestamp != "2026-01-01T00:00:00Z" {
+		t.Errorf("Unique size timestamp = %s, expected 2026-01-01T00:00:00Z", updated[0].UniqueSizeTimestamp)
+	}
+
+	if requests != 1 {
+		t.Errorf("Re-read the backups with %d requests, expected 1", requests)
+	}
+}
+
+func TestCalculateUniqueSizesNotAffected(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("The backup not reported by its task should not be re-read")
+	})
+
+	apiHandler.HandleFunc("/backups/1/calculate_unique_size", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "task1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	// The task reports another backup
+	apiHandler.HandleFunc("/tasks/task1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "COMPLETE", "id": "task1", "percent_complete": 100, "affected_objects":[{"object_type":"backup", "object_id":"2"}]}}`)
+	})
+
+	backups := []*Backup{{Id: "1", client: client}}
+	_, err := client.Backups.CalculateUniqueSizes(backups, &WaitAllOptions{PollsPerSecond: 20})
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || !errors.Is(multiErr.Errors["1"], ErrTaskFailed) {
+		t.Errorf("Returned %v, expected a task error for backup 1", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build go1.19
+// +build go1.19
+
+package ovc
+
+import "crypto/x509"
+
+// copyCertPool returns a copy of the pool, to add certificates to it
+// without changing the pool of the caller.
+func copyCertPool(pool *x509.CertPool) (*x509.CertPool, error) {
+	return pool.Clone(), nil
+}
@@ -0,0 +1,16 @@
+//go:build !go1.19
+// +build !go1.19
+
+package ovc
+
+import (
+	"crypto/x509"
+	"errors"
+)
+
+// copyCertPool returns a copy of the pool, to add certificates to it
+// without changing the pool of the caller.
+// Certificate pools can't be copied before Go 1.19.
+func copyCertPool(pool *x509.CertPool) (*x509.CertPool, error) {
+	return nil, errors.New("Pass the CA certificates either with WithCACertFile or in the RootCAs of WithTLSConfig")
+}
@@ -1,9 +1,9 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"log"
+	"sync"
 	"time"
 )
 
@@ -47,7 +47,50 @@ type Datastore struct {
 	Shares                                 []Shares  `json:"shares,omitempty"`
 }
 
+// DatastoreFilter is a typed filter of the datastores.
+// See GetAll for the meaning of the fields.
+type DatastoreFilter struct {
+	Ids                                     []string
+	Names                                   []string
+	MinSize                                 int64
+	MaxSize                                 int64
+	CreatedBefore                           time.Time
+	CreatedAfter                            time.Time
+	OmniStackClusterIds                     []string
+	OmniStackClusterNames                   []string
+	ComputeClusterParentHypervisorObjectIds []string
+	ComputeClusterParentNames               []string
+	HypervisorManagementSystemNames         []string
+	PolicyIds                               []string
+	PolicyNames                             []string
+	HypervisorObjectIds                     []string
+	MountDirectories                        []string
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f DatastoreFilter) Filters() map[string]string {
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.int("min_size", f.MinSize)
+	values.int("max_size", f.MaxSize)
+	values.time("created_before", f.CreatedBefore)
+	values.time("created_after", f.CreatedAfter)
+	values.list("omnistack_cluster_id", f.OmniStackClusterIds)
+	values.list("omnistack_cluster_name", f.OmniStackClusterNames)
+	values.list("compute_cluster_parent_hypervisor_object_id", f.ComputeClusterParentHypervisorObjectIds)
+	values.list("compute_cluster_parent_name", f.ComputeClusterParentNames)
+	values.list("hypervisor_management_system_name", f.HypervisorManagementSystemNames)
+	values.list("policy_id", f.PolicyIds)
+	values.list("policy_name", f.PolicyNames)
+	values.list("hypervisor_object_id", f.HypervisorObjectIds)
+	values.list("mount_directory", f.MountDirectories)
+
+	return values
+}
+
 // GetAll returns all the datastores filtered by the query parameters.
+// Build the filters with DatastoreFilter.
 // Filters:
 //   id: The unique identifier (UID) of the datastores to return
 //     Accepts: Single value, comma-separated list
@@ -87,6 +130,11 @@ type Datastore struct {
 //   mount_directory: A comma-separated list of fields to include in the returned objects
 //     Default: Returns all fields
 func (d *DatastoreResource) GetAll(params GetAllParams) (*DatastoreList, error) {
+	return d.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*DatastoreList, error) {
 	var (
 		path          = "/datastores"
 		datastoreList DatastoreList
@@ -94,7 +142,7 @@ func (d *DatastoreResource) GetAll(params GetAllParams) (*DatastoreList, error)
 
 	qrStr := params.QueryString()
 
-	resp, err := d.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := d.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &datastoreList, err
 	}
@@ -107,27 +155,107 @@ func (d *DatastoreResource) GetAll(params GetAllParams) (*DatastoreList, error)
 	return &datastoreList, nil
 }
 
+// GetAllPages returns all the datastores matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of datastores keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (d *DatastoreResource) GetAllPages(params GetAllParams) (*DatastoreList, error) {
+	return d.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*DatastoreList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*Datastore
+	)
+
+	count, err := fetchAllPages(ctx, params, d.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		datastoreList, err := d.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*Datastore{}
+		}
+		pages[page] = datastoreList.Members
+
+		return datastoreList.Count, len(datastoreList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	datastoreList := &DatastoreList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		datastoreList.Members = append(datastoreList.Members, pages[page]...)
+	}
+
+	return datastoreList, nil
+}
+
+// Iterate calls fn with every datastore matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (d *DatastoreResource) Iterate(params GetAllParams, fn func(datastore *Datastore) error) error {
+	return d.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(datastore *Datastore) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		datastoreList, err := d.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, datastore := range datastoreList.Members {
+			err = fn(datastore)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return datastoreList.Count, len(datastoreList.Members), nil
+	})
+}
+
 // GetBy gets datastores with single filter
 func (d *DatastoreResource) GetBy(field string, value string) ([]*Datastore, error) {
+	return d.GetByWithContext(context.Background(), field, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) GetByWithContext(ctx context.Context, field string, value string) ([]*Datastore, error) {
+	var datastores []*Datastore
 	filters := map[string]string{field: value}
-	datastoreList, err := d.GetAll(GetAllParams{Filters: filters})
+	err := d.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(datastore *Datastore) error {
+		datastores = append(datastores, datastore)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	datastores := datastoreList.Members
-
 	return datastores, nil
 }
 
 // GetByName searches for a datastore by its name
 func (d *DatastoreResource) GetByName(name string) (*Datastore, error) {
-	datastores, err := d.GetBy("name", name)
+	return d.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) GetByNameWithContext(ctx context.Context, name string) (*Datastore, error) {
+	datastores, err := d.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -135,15 +263,19 @@ func (d *DatastoreResource) GetByName(name string) (*Datastore, error) {
 		return datastores[0], nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a datastore by its id.
 func (d *DatastoreResource) GetById(id string) (*Datastore, error) {
-	datastores, err := d.GetBy("id", id)
+	return d.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (d *DatastoreResource) GetByIdWithContext(ctx context.Context, id string) (*Datastore, error) {
+	datastores, err := d.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -151,5 +283,5 @@ func (d *DatastoreResource) GetById(id string) (*Datastore, error) {
 		return datastores[0], nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
@@ -0,0 +1,177 @@
+package ovc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Errors of the OVC API endpoints.
+// An *APIError matches them with errors.Is based on its HTTP status code.
+var (
+	ErrBadRequest   = errors.New("Bad request")
+	ErrUnauthorized = errors.New("Unauthorized")
+	ErrNotFound     = errors.New("Resource doesn't exist")
+	ErrConflict     = errors.New("Conflict")
+	ErrServerError  = errors.New("Server error")
+)
+
+// APIError is an error response of an OVC API endpoint.
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+
+	// Method and URL of the request
+	Method string
+	URL    string
+
+	// Fields of the error response
+	Exception string
+	Path      string
+	ErrorType string // The "error" field, e.g. invalid_token
+	Message   string
+}
+
+// newAPIError creates an APIError for the response of the request.
+func newAPIError(req *http.Request, statusCode int, respErr *OVCRespError) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Exception:  respErr.Exception,
+		Path:       respErr.Path,
+		ErrorType:  respErr.Error,
+		Message:    respErr.Message,
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Error: Status code:%d - %s (%s %s)", e.StatusCode, e.Message, e.Method, e.URL)
+}
+
+// Is reports whether the error matches one of the sentinel errors
+// of the OVC API endpoints.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// respError converts the error into the error response of Client.Do.
+func (e *APIError) respError() *OVCRespError {
+	return &OVCRespError{
+		Exception: e.Exception,
+		Path:      e.Path,
+		Error:     e.ErrorType,
+		Message:   e.Message,
+		Status:    fmt.Sprint(e.StatusCode),
+	}
+}
+
+// ErrTaskFailed is matched by the errors of the tasks which did not succeed.
+var ErrTaskFailed = errors.New("Task failed")
+
+// TaskErrorCode is an error code of the OVC tasks.
+// The task errors with the code match it with errors.Is,
+// e.g. errors.Is(err, ovc.TaskErrorCode(code)).
+type TaskErrorCode int
+
+func (c TaskErrorCode) Error() string {
+	return fmt.Sprintf("Task error code %d", int(c))
+}
+
+// TaskError is returned when a task of the OVC fails,
+// or finishes without the objects it should have created.
+type TaskError struct {
+	// Operation which started the task, e.g. VirtualMachine.Clone
+	// Empty for the tasks not started by the methods of the resources.
+	Operation string
+
+	// Fields of the task
+	TaskId    string
+	State     string
+	ErrorCode int
+	Messages  []string
+}
+
+// newTaskError creates a TaskError from the current state of the task.
+// Callers must hold the lock of a task shared with other goroutines.
+func newTaskError(task *Task) *TaskError {
+	return &TaskError{
+		Operation: task.Operation,
+		TaskId:    task.Id,
+		State:     task.State,
+		ErrorCode: task.ErrorCode,
+		Messages:  task.Messages,
+	}
+}
+
+func (e *TaskError) Error() string {
+	msg := fmt.Sprintf("Task %s %s. Error code: %d", e.TaskId, e.State, e.ErrorCode)
+	if e.Operation != "" {
+		msg = e.Operation + " was not successful. " + msg
+	}
+	if len(e.Messages) > 0 {
+		msg += " - " + strings.Join(e.Messages, "; ")
+	}
+
+	return msg
+}
+
+// Is matches ErrTaskFailed, and the TaskErrorCode of the task.
+func (e *TaskError) Is(target error) bool {
+	if target == ErrTaskFailed {
+		return true
+	}
+
+	code, ok := target.(TaskErrorCode)
+	return ok && e.ErrorCode != 0 && int(code) == e.ErrorCode
+}
+
+// MultiError is returned by the bulk operations, e.g. Tasks.WaitAll,
+// when some of the tasks or resources failed.
+// It matches the errors of each of them with errors.Is.
+type MultiError struct {
+	// Errors of the failed tasks or resources by id
+	// Each bulk operation documents which ids it uses.
+	Errors map[string]error
+}
+
+func (e *MultiError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %v", id, e.Errors[id])
+	}
+
+	return fmt.Sprintf("%d failed: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// Is reports whether the error of any task or resource matches target.
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
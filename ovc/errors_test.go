@@ -0,0 +1,175 @@
+package ovc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception": "ObjectNotFoundException", "path": "/api/test/path",
+			"error": "Not Found", "message": "Object not found"}`)
+	})
+
+	_, err := client.DoRequest("GET", "/test/path", "a=1", nil, nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Returned error = %v, expected *APIError", err)
+	}
+
+	expected := &APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     "GET",
+		URL:        client.OVCIP + "/test/path?a=1",
+		Exception:  "ObjectNotFoundException",
+		Path:       "/api/test/path",
+		ErrorType:  "Not Found",
+		Message:    "Object not found",
+	}
+	if *apiErr != *expected {
+		t.Errorf("Returned = %+v, expected %+v", apiErr, expected)
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is(%v, ErrNotFound) = false, expected true", err)
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Errorf("errors.Is(%v, ErrConflict) = true, expected false", err)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		target     error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusBadGateway, ErrServerError},
+	}
+
+	for _, test := range tests {
+		err := error(&APIError{StatusCode: test.statusCode})
+		if !errors.Is(err, test.target) {
+			t.Errorf("errors.Is(%d, %v) = false, expected true", test.statusCode, test.target)
+		}
+	}
+}
+
+func TestAPIErrorWithoutJSONBody(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `<html>Bad Gateway</html>`)
+	})
+
+	// Gateway errors are retried by default
+	client.RetryPolicy = nil
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Returned error = %v, expected %v", err, ErrServerError)
+	}
+}
+
+func TestGetByNameNotFound(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/virtual_machines", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"offset": 0, "limit": 500, "count": 0, "virtual_machines":[]}`)
+	})
+
+	_, err := client.VirtualMachines.GetByName("testname")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Returned error = %v, expected %v", err, ErrNotFound)
+	}
+}
+
+func TestTaskError(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockGetVMById(apiHandler)
+	apiHandler.HandleFunc("/virtual_machines/1/clone", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "2"}}`)
+	})
+	apiHandler.HandleFunc("/tasks/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task":{"state": "FAILED", "id": "2", "error_code": 1234,
+			"messages": [{"message": "Not enough space"}, "Clone aborted"]}}`)
+	})
+
+	errNoSpace := TaskErrorCode(1234)
+
+	vm, err := client.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = vm.Clone("testvm", false)
+	if !errors.Is(err, ErrTaskFailed) || !errors.Is(err, errNoSpace) {
+		t.Errorf("Returned error = %v, expected to match ErrTaskFailed and the error code", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("Returned error = %v should not match ErrNotFound", err)
+	}
+
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("Returned error = %v, expected a *TaskError", err)
+	}
+
+	expected := "VirtualMachine.Clone was not successful. Task 2 FAILED. Error code: 1234 - Not enough space; Clone aborted"
+	if got := taskErr.Error(); got != expected {
+		t.Errorf("Error() = %q, expected %q", got, expected)
+	}
+}
+
+func TestTaskErrorWithoutAffectedObjects(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockGetVMById(apiHandler)
+	apiHandler.HandleFunc("/virtual_machines/1/move", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task":{"state": "COMPLETED", "id": "3", "error_code": 7, "messages": "Nothing to move"}}`)
+	})
+
+	vm, err := client.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = vm.Move("testvm", &Datastore{Id: "1"})
+	expected := "VirtualMachine.Move was not successful. Task 3 COMPLETED. Error code: 7 - Nothing to move"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Returned error = %v, expected %s", err, expected)
+	}
+}
+
+func TestTaskErrorWithoutMessages(t *testing.T) {
+	for _, messages := range []string{`null`, `""`, `[null, ""]`} {
+		var task Task
+		err := json.Unmarshal([]byte(`{"state": "FAILED", "id": "1", "error_code": 5, "messages": `+messages+`}`), &task)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "Task 1 FAILED. Error code: 5"
+		if got := newTaskError(&task).Error(); got != expected {
+			t.Errorf("Error() with messages %s = %q, expected %q", messages, got, expected)
+		}
+	}
+}
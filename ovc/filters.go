@@ -0,0 +1,51 @@
+package ovc
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterValues builds the filters of GetAllParams from the typed filters
+// of the resources. Unset fields are left out.
+type filterValues map[string]string
+
+// list sets a comma-separated list of values.
+func (f filterValues) list(key string, values []string) {
+	if len(values) > 0 {
+		f[key] = strings.Join(values, ",")
+	}
+}
+
+// string sets a single value.
+func (f filterValues) string(key string, value string) {
+	if value != "" {
+		f[key] = value
+	}
+}
+
+// time sets a time in ISO-8601 form, in UTC.
+func (f filterValues) time(key string, t time.Time) {
+	if !t.IsZero() {
+		f[key] = t.UTC().Format(time.RFC3339)
+	}
+}
+
+// int sets a number.
+func (f filterValues) int(key string, value int64) {
+	if value != 0 {
+		f[key] = strconv.FormatInt(value, 10)
+	}
+}
+
+// bool sets an optional boolean.
+func (f filterValues) bool(key string, value *bool) {
+	if value != nil {
+		f[key] = strconv.FormatBool(*value)
+	}
+}
+
+// Bool returns a pointer to b, to set the optional boolean fields of the filters.
+func Bool(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,74 @@
+package ovc
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVirtualMachineFilter(t *testing.T) {
+	filter := VirtualMachineFilter{
+		Names:                []string{"vm1", "vm2*"},
+		States:               []VMState{VMStateAlive, VMStateRemoved},
+		PolicyNames:          []string{"Fixed Default Backup Policy"},
+		CreatedAfter:         time.Date(2020, 1, 27, 5, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+		HypervisorIsTemplate: Bool(false),
+	}
+
+	expected := map[string]string{
+		"name":                   "vm1,vm2*",
+		"state":                  "ALIVE,REMOVED",
+		"policy_name":            "Fixed Default Backup Policy",
+		"created_after":          "2020-01-27T10:00:00Z",
+		"hypervisor_is_template": "false",
+	}
+	if got := filter.Filters(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Filters() = %v, expected %v", got, expected)
+	}
+}
+
+func TestBackupFilter(t *testing.T) {
+	filter := BackupFilter{
+		Types:           []BackupType{BackupTypeManual},
+		SizeMin:         1024,
+		SentDurationMax: 90 * time.Second,
+		ExpiresBefore:   time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	expected := map[string]string{
+		"type":              "MANUAL",
+		"size_min":          "1024",
+		"sent_duration_max": "90",
+		"expires_before":    "2020-02-01T00:00:00Z",
+	}
+	if got := filter.Filters(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Filters() = %v, expected %v", got, expected)
+	}
+}
+
+func TestEmptyFilter(t *testing.T) {
+	if got := (HostFilter{}).Filters(); len(got) != 0 {
+		t.Errorf("Filters() = %v, expected no filters", got)
+	}
+}
+
+func TestGetAllWithFilter(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/omnistack_clusters", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("arbiter_connected") != "true" || query.Get("type") != "OMNISTACK,CLOUD" {
+			t.Errorf("Request query %s is missing the filters", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"omnistack_clusters": []}`)
+	})
+
+	filter := OmniStackClusterFilter{Types: []string{"OMNISTACK", "CLOUD"}, ArbiterConnected: Bool(true)}
+	_, err := client.OmniStackClusters.GetAll(GetAllParams{Filters: filter.Filters()})
+	if err != nil {
+		t.Error(err)
+	}
+}
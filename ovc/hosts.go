@@ -1,9 +1,9 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"log"
+	"sync"
 )
 
 // HostResource handles communications with the the Host resource methods
@@ -54,7 +54,66 @@ type Host struct {
 	HypervisorManagementSystemName         string   `json:"hypervisor_management_system_name,omitempty"`
 }
 
+// HostFilter is a typed filter of the hosts.
+// See GetAll for the meaning of the fields.
+type HostFilter struct {
+	Ids                                     []string
+	Names                                   []string
+	Types                                   []string
+	Models                                  []string
+	Versions                                []string
+	HypervisorManagementSystems             []string
+	HypervisorManagementSystemNames         []string
+	HypervisorObjectIds                     []string
+	ComputeClusterNames                     []string
+	ComputeClusterHypervisorObjectIds       []string
+	ManagementIPs                           []string
+	StorageIPs                              []string
+	FederationIPs                           []string
+	VirtualControllerNames                  []string
+	ComputeClusterParentNames               []string
+	ComputeClusterParentHypervisorObjectIds []string
+	PolicyEnabled                           *bool
+	CurrentFeatureLevelMin                  int
+	CurrentFeatureLevelMax                  int
+	PotentialFeatureLevelMin                int
+	PotentialFeatureLevelMax                int
+	UpgradeStates                           []string
+	CanRollback                             *bool
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f HostFilter) Filters() map[string]string {
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.list("type", f.Types)
+	values.list("model", f.Models)
+	values.list("version", f.Versions)
+	values.list("hypervisor_management_system", f.HypervisorManagementSystems)
+	values.list("hypervisor_management_system_name", f.HypervisorManagementSystemNames)
+	values.list("hypervisor_object_id", f.HypervisorObjectIds)
+	values.list("compute_cluster_name", f.ComputeClusterNames)
+	values.list("compute_cluster_hypervisor_object_id", f.ComputeClusterHypervisorObjectIds)
+	values.list("management_ip", f.ManagementIPs)
+	values.list("storage_ip", f.StorageIPs)
+	values.list("federation_ip", f.FederationIPs)
+	values.list("virtual_controller_name", f.VirtualControllerNames)
+	values.list("compute_cluster_parent_name", f.ComputeClusterParentNames)
+	values.list("compute_cluster_parent_hypervisor_object_id", f.ComputeClusterParentHypervisorObjectIds)
+	values.bool("policy_enabled", f.PolicyEnabled)
+	values.int("current_feature_level_min", int64(f.CurrentFeatureLevelMin))
+	values.int("current_feature_level_max", int64(f.CurrentFeatureLevelMax))
+	values.int("potential_feature_level_min", int64(f.PotentialFeatureLevelMin))
+	values.int("potential_feature_level_max", int64(f.PotentialFeatureLevelMax))
+	values.list("upgrade_state", f.UpgradeStates)
+	values.bool("can_rollback", f.CanRollback)
+
+	return values
+}
+
 // GetAll returns all the hosts filtered by the query parameters.
+// Build the filters with HostFilter.
 // Filters:
 //   id: The unique identifier (UID) of the host
 //     Accepts: Single value, comma-separated list
@@ -132,6 +191,11 @@ type Host struct {
 //     True: The current HPE OmniStack software for the host can roll back to the previous version.
 //     False: The current HPE OmniStack software for the host cannot roll back to the previous version.
 func (h *HostResource) GetAll(params GetAllParams) (*HostList, error) {
+	return h.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (h *HostResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*HostList, error) {
 	var (
 		path     = "/hosts"
 		hostList HostList
@@ -139,7 +203,7 @@ func (h *HostResource) GetAll(params GetAllParams) (*HostList, error) {
 
 	qrStr := params.QueryString()
 
-	resp, err := h.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := h.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &hostList, err
 	}
@@ -152,27 +216,107 @@ func (h *HostResource) GetAll(params GetAllParams) (*HostList, error) {
 	return &hostList, nil
 }
 
+// GetAllPages returns all the hosts matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of hosts keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (h *HostResource) GetAllPages(params GetAllParams) (*HostList, error) {
+	return h.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (h *HostResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*HostList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*Host
+	)
+
+	count, err := fetchAllPages(ctx, params, h.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		hostList, err := h.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*Host{}
+		}
+		pages[page] = hostList.Members
+
+		return hostList.Count, len(hostList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hostList := &HostList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		hostList.Members = append(hostList.Members, pages[page]...)
+	}
+
+	return hostList, nil
+}
+
+// Iterate calls fn with every host matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (h *HostResource) Iterate(params GetAllParams, fn func(host *Host) error) error {
+	return h.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (h *HostResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(host *Host) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		hostList, err := h.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, host := range hostList.Members {
+			err = fn(host)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return hostList.Count, len(hostList.Members), nil
+	})
+}
+
 // GetBy searches for hosts with single filter.
 func (h *HostResource) GetBy(field string, value string) ([]*Host, error) {
+	return h.GetByWithContext(context.Background(), field, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (h *HostResource) GetByWithContext(ctx context.Context, field string, value string) ([]*Host, error) {
+	var hosts []*Host
 	filters := map[string]string{field: value}
-	hostList, err := h.GetAll(GetAllParams{Filters: filters})
+	err := h.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(host *Host) error {
+		hosts = append(hosts, host)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	hosts := hostList.Members
-
 	return hosts, nil
 }
 
 // GetByName searches for a host by its name
 func (h *HostResource) GetByName(name string) (*Host, error) {
-	hosts, err := h.GetBy("name", name)
+	return h.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (h *HostResource) GetByNameWithContext(ctx context.Context, name string) (*Host, error) {
+	hosts, err := h.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -181,15 +325,19 @@ func (h *HostResource) GetByName(name string) (*Host, error) {
 		return host, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a host by its id
 func (h *HostResource) GetById(id string) (*Host, error) {
-	hosts, err := h.GetBy("id", id)
+	return h.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (h *HostResource) GetByIdWithContext(ctx context.Context, id string) (*Host, error) {
+	hosts, err := h.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -198,5 +346,5 @@ func (h *HostResource) GetById(id string) (*Host, error) {
 		return host, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
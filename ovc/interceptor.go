@@ -0,0 +1,28 @@
+package ovc
+
+import (
+	"net/http"
+)
+
+// Invoker sends a request to the OVC and returns the body of the response.
+// Error responses of the OVC are returned as *APIError.
+type Invoker func(req *http.Request) ([]byte, error)
+
+// Interceptor wraps the requests to the OVC, including the login requests
+// and every retry attempt. It calls next to send the request, and can
+// change the request before and inspect the response or error after.
+// The body of the request can be read with req.GetBody.
+type Interceptor func(req *http.Request, next Invoker) ([]byte, error)
+
+// chain wraps the invoker with the interceptors of the client.
+// The first interceptor is the outermost one.
+func (c *Client) chain(invoke Invoker) Invoker {
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.Interceptors[i], invoke
+		invoke = func(req *http.Request) ([]byte, error) {
+			return interceptor(req, next)
+		}
+	}
+
+	return invoke
+}
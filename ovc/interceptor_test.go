@@ -0,0 +1,104 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "X-Request-Id", "1")
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "X-Request-Id", "1")
+		fmt.Fprint(w, `{"id": "1"}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	var calls []string
+	audit := func(req *http.Request, next Invoker) ([]byte, error) {
+		var body []byte
+		if req.GetBody != nil {
+			reader, _ := req.GetBody()
+			body, _ = ioutil.ReadAll(reader)
+		}
+
+		data, err := next(req)
+		calls = append(calls, fmt.Sprintf("%s %s %s -> %s %v", req.Method, req.URL.Path, body, data, err))
+		return data, err
+	}
+	requestID := func(req *http.Request, next Invoker) ([]byte, error) {
+		req.Header.Set("X-Request-Id", "1")
+		return next(req)
+	}
+
+	client, err := NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "pass"), WithInterceptors(audit, requestID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DoRequest("POST", "/test/path", "", map[string]string{"name": "vm"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"POST /oauth/token grant_type=password&password=pass&username=user -> {\"access_token\": \"12345\"} <nil>",
+		"POST /test/path {\"name\":\"vm\"}\n -> {\"id\": \"1\"} <nil>",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Intercepted calls = %q, expected %q", calls, expected)
+	}
+}
+
+func TestInterceptorsSeeErrors(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not found"}`)
+	})
+
+	var interceptedErr error
+	client.Interceptors = []Interceptor{func(req *http.Request, next Invoker) ([]byte, error) {
+		data, err := next(req)
+		interceptedErr = err
+		return data, err
+	}}
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Returned error = %v, expected ErrNotFound", err)
+	}
+	if !errors.Is(interceptedErr, ErrNotFound) {
+		t.Errorf("Intercepted error = %v, expected ErrNotFound", interceptedErr)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	client.Interceptors = []Interceptor{func(req *http.Request, next Invoker) ([]byte, error) {
+		return []byte(`{"cached": true}`), nil
+	}}
+
+	data, err := client.DoRequest("GET", "/not/served", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"cached": true}` {
+		t.Errorf("Returned %s, expected the response of the interceptor", data)
+	}
+}
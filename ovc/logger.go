@@ -0,0 +1,211 @@
+package ovc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Logger receives the log events of an OVC client, e.g. requests,
+// retries, token refreshes and task polling.
+// keysAndValues are alternating keys and values describing the event.
+// Secrets are redacted before they are passed to the logger.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// LogLevel is the severity of a log event.
+type LogLevel int
+
+// Log levels
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// stdLogger writes the log events with a standard library logger.
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+// NewStdLogger creates a Logger which writes the events with at least
+// the given level to logger, as key=value pairs.
+func NewStdLogger(logger *log.Logger, level LogLevel) Logger {
+	return &stdLogger{logger: logger, level: level}
+}
+
+func (s *stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.log(LevelDebug, msg, keysAndValues)
+}
+
+func (s *stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.log(LevelInfo, msg, keysAndValues)
+}
+
+func (s *stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.log(LevelWarn, msg, keysAndValues)
+}
+
+func (s *stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.log(LevelError, msg, keysAndValues)
+}
+
+func (s *stdLogger) log(level LogLevel, msg string, keysAndValues []interface{}) {
+	if level < s.level {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "level=%s msg=%q", level, msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%q", keysAndValues[i], fmt.Sprint(value))
+	}
+
+	s.logger.Println(b.String())
+}
+
+// noopLogger drops all the log events.
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (noopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (noopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (noopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+// log returns the logger of the client.
+func (c *Client) log() Logger {
+	if c.Logger == nil {
+		return noopLogger{}
+	}
+
+	return c.Logger
+}
+
+// Replaces the secrets in the logs
+const redacted = "REDACTED"
+
+// Names of headers, form fields and JSON fields with secrets
+var secretNames = []string{"authorization", "password", "token", "secret"}
+
+// isSecret reports whether the header or field name holds a secret.
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactHeader returns the request headers without secrets.
+func redactHeader(header http.Header) map[string]string {
+	result := map[string]string{}
+	for name, values := range header {
+		if isSecret(name) {
+			result[name] = redacted
+		} else {
+			result[name] = strings.Join(values, ",")
+		}
+	}
+
+	return result
+}
+
+// redactBody returns the request body without secrets.
+// Handles URL encoded forms and JSON bodies.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted
+		}
+		for name := range form {
+			if isSecret(name) {
+				form.Set(name, redacted)
+			}
+		}
+		return form.Encode()
+	}
+
+	var data interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return redacted
+	}
+
+	out, _ := json.Marshal(redactJSON(data))
+	return string(out)
+}
+
+// redactJSON replaces the values of the secret fields of a decoded JSON value.
+func redactJSON(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if isSecret(name) {
+				value[name] = redacted
+			} else {
+				value[name] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactJSON(item)
+		}
+	}
+
+	return data
+}
+
+// logRequest logs the request with its headers and body.
+func (c *Client) logRequest(req *http.Request) {
+	if c.Logger == nil {
+		return
+	}
+
+	keysAndValues := []interface{}{"method", req.Method, "url", req.URL.String(), "header", redactHeader(req.Header)}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			data, _ := ioutil.ReadAll(body)
+			keysAndValues = append(keysAndValues, "body", redactBody(req.Header.Get("Content-Type"), data))
+		}
+	}
+
+	c.Logger.Debug("OVC request", keysAndValues...)
+}
@@ -0,0 +1,146 @@
+package ovc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logEvent struct {
+	level         LogLevel
+	msg           string
+	keysAndValues []interface{}
+}
+
+// testLogger records the log events.
+type testLogger struct {
+	mu     sync.Mutex
+	events []logEvent
+}
+
+func (l *testLogger) add(level LogLevel, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, logEvent{level, msg, keysAndValues})
+}
+
+func (l *testLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.add(LevelDebug, msg, keysAndValues)
+}
+
+func (l *testLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.add(LevelInfo, msg, keysAndValues)
+}
+
+func (l *testLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.add(LevelWarn, msg, keysAndValues)
+}
+
+func (l *testLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.add(LevelError, msg, keysAndValues)
+}
+
+// String returns all the events in a single string.
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b strings.Builder
+	for _, event := range l.events {
+		fmt.Fprintln(&b, event.level, event.msg, event.keysAndValues)
+	}
+	return b.String()
+}
+
+func (l *testLogger) has(msg string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, event := range l.events {
+		if event.msg == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestImportDoesNotChangeGlobalLogger(t *testing.T) {
+	if log.Writer() == ioutil.Discard {
+		t.Error("Global logger output is discarded")
+	}
+}
+
+func TestLoggerRedactsSecrets(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "secret-token"}`)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	logger := &testLogger{}
+	client, err := NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "secret-password"), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{"guest_username": "user", "guest_password": "secret-guest-password"}
+	_, err = client.DoRequest("POST", "/test/path", "", body, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs := logger.String()
+	for _, secret := range []string{"secret-password", "secret-token", "secret-guest-password"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("Logs contain the secret %s:\n%s", secret, logs)
+		}
+	}
+
+	if !logger.has("OVC request") || !logger.has("OVC response") || !logger.has("Access token refreshed") {
+		t.Errorf("Logs are missing request, response or token events:\n%s", logs)
+	}
+}
+
+func TestLoggerRetryEvents(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	logger := &testLogger{}
+	client.Logger = logger
+	client.RetryPolicy = testRetryPolicy()
+
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	client.DoRequest("GET", "/test/path", "", nil, nil)
+
+	if !logger.has("Retrying OVC request") {
+		t.Errorf("Logs are missing the retry events:\n%s", logger)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LevelInfo)
+
+	logger.Debug("dropped")
+	logger.Info("kept", "key", "value")
+
+	expected := `level=info msg="kept" key="value"` + "\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Logged %q, expected %q", got, expected)
+	}
+}
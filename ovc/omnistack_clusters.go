@@ -1,9 +1,9 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"log"
+	"sync"
 )
 
 // OmniStackClusterResource handles communications with the the Cluster resource methods
@@ -46,7 +46,38 @@ type OmniStackCluster struct {
 	IwoEnabled                     bool                   `json:"iwo_enabled,omitempty"`
 }
 
+// OmniStackClusterFilter is a typed filter of the OmniStack Clusters.
+// See GetAll for the meaning of the fields.
+type OmniStackClusterFilter struct {
+	Ids                             []string
+	Names                           []string
+	HypervisorObjectIds             []string
+	HypervisorObjectParentIds       []string
+	HypervisorObjectParentNames     []string
+	HypervisorManagementSystemNames []string
+	Types                           []string
+	ArbiterAddresses                []string
+	ArbiterConnected                *bool
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f OmniStackClusterFilter) Filters() map[string]string {
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.list("hypervisor_object_id", f.HypervisorObjectIds)
+	values.list("hypervisor_object_parent_id", f.HypervisorObjectParentIds)
+	values.list("hypervisor_object_parent_name", f.HypervisorObjectParentNames)
+	values.list("hypervisor_management_system_name", f.HypervisorManagementSystemNames)
+	values.list("type", f.Types)
+	values.list("arbiter_address", f.ArbiterAddresses)
+	values.bool("arbiter_connected", f.ArbiterConnected)
+
+	return values
+}
+
 // GetAll returns all the OmniStack Clusters filtered by the query parameters.
+// Build the filters with OmniStackClusterFilter.
 // Filters:
 //   id: The unique identifier (UID) of the omnistack_clusters to return
 //     Accepts: Single value, comma-separated list
@@ -82,6 +113,11 @@ type OmniStackCluster struct {
 //     False: Only returns omnistack_clusters not connected to Arbiters that you identified
 //       in arbiter_address
 func (o *OmniStackClusterResource) GetAll(params GetAllParams) (*OmniStackClusterList, error) {
+	return o.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*OmniStackClusterList, error) {
 	var (
 		path        = "/omnistack_clusters"
 		clusterList OmniStackClusterList
@@ -89,7 +125,7 @@ func (o *OmniStackClusterResource) GetAll(params GetAllParams) (*OmniStackCluste
 
 	qrStr := params.QueryString()
 
-	resp, err := o.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := o.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &clusterList, err
 	}
@@ -102,27 +138,107 @@ func (o *OmniStackClusterResource) GetAll(params GetAllParams) (*OmniStackCluste
 	return &clusterList, nil
 }
 
+// GetAllPages returns all the OmniStack clusters matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of OmniStack clusters keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (o *OmniStackClusterResource) GetAllPages(params GetAllParams) (*OmniStackClusterList, error) {
+	return o.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*OmniStackClusterList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*OmniStackCluster
+	)
+
+	count, err := fetchAllPages(ctx, params, o.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		clusterList, err := o.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*OmniStackCluster{}
+		}
+		pages[page] = clusterList.Members
+
+		return clusterList.Count, len(clusterList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterList := &OmniStackClusterList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		clusterList.Members = append(clusterList.Members, pages[page]...)
+	}
+
+	return clusterList, nil
+}
+
+// Iterate calls fn with every OmniStack cluster matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (o *OmniStackClusterResource) Iterate(params GetAllParams, fn func(cluster *OmniStackCluster) error) error {
+	return o.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(cluster *OmniStackCluster) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		clusterList, err := o.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, cluster := range clusterList.Members {
+			err = fn(cluster)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return clusterList.Count, len(clusterList.Members), nil
+	})
+}
+
 // GetBy searches for OmniStack Clusters with single filter.
 func (o *OmniStackClusterResource) GetBy(field string, value string) ([]*OmniStackCluster, error) {
+	return o.GetByWithContext(context.Background(), field, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) GetByWithContext(ctx context.Context, field string, value string) ([]*OmniStackCluster, error) {
+	var clusters []*OmniStackCluster
 	filters := map[string]string{field: value}
-	clusterList, err := o.GetAll(GetAllParams{Filters: filters})
+	err := o.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(cluster *OmniStackCluster) error {
+		clusters = append(clusters, cluster)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	clusters := clusterList.Members
-
 	return clusters, nil
 }
 
 // GetByName searches for an OmniStack Cluster by its name
 func (o *OmniStackClusterResource) GetByName(name string) (*OmniStackCluster, error) {
-	clusters, err := o.GetBy("name", name)
+	return o.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) GetByNameWithContext(ctx context.Context, name string) (*OmniStackCluster, error) {
+	clusters, err := o.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -131,15 +247,19 @@ func (o *OmniStackClusterResource) GetByName(name string) (*OmniStackCluster, er
 		return cluster, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for an OmniStack Cluster by its id.
 func (o *OmniStackClusterResource) GetById(id string) (*OmniStackCluster, error) {
-	clusters, err := o.GetBy("id", id)
+	return o.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (o *OmniStackClusterResource) GetByIdWithContext(ctx context.Context, id string) (*OmniStackCluster, error) {
+	clusters, err := o.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -148,5 +268,5 @@ func (o *OmniStackClusterResource) GetById(id string) (*OmniStackCluster, error)
 		return cluster, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
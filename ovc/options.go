@@ -0,0 +1,222 @@
+package ovc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientOption configures an OVC client created with NewClientWithOptions.
+type ClientOption func(*clientOptions) error
+
+// Settings collected from the client options.
+type clientOptions struct {
+	username    string
+	password    string
+	tokenSource TokenSource
+
+	httpClient     *http.Client
+	timeout        time.Duration
+	requestTimeout time.Duration
+	tlsConfig      *tls.Config
+	caCertFile     string
+	insecure       bool
+	proxy          *url.URL
+	userAgent      string
+	retryPolicy    *RetryPolicy
+	logger         Logger
+	interceptors   []Interceptor
+	pageWorkers    int
+	pollPolicy     *PollPolicy
+	taskJournal    *TaskJournal
+}
+
+// WithPassword sets the credentials used to log in to the OVC.
+func WithPassword(username string, password string) ClientOption {
+	return func(o *clientOptions) error {
+		o.username = username
+		o.password = password
+		return nil
+	}
+}
+
+// WithTokenSource sets the source of the access tokens of the client.
+func WithTokenSource(src TokenSource) ClientOption {
+	return func(o *clientOptions) error {
+		o.tokenSource = src
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http client used to communicate with the OVC.
+// The TLS, proxy and request timeout options are not applied to this client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *clientOptions) error {
+		o.httpClient = client
+		return nil
+	}
+}
+
+// WithTimeout limits the time of every call to the OVC,
+// including its retries and token refreshes.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		o.timeout = timeout
+		return nil
+	}
+}
+
+// WithRequestTimeout limits the time of every http request to the OVC.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		o.requestTimeout = timeout
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the connections to the OVC,
+// e.g. to use client certificates.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+// WithCACertFile trusts the certificates of the PEM file at path
+// in addition to the system root certificates.
+func WithCACertFile(path string) ClientOption {
+	return func(o *clientOptions) error {
+		o.caCertFile = path
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the OVC certificate.
+// Only use it for testing, as the connection is open to man-in-the-middle attacks.
+func WithInsecureSkipVerify() ClientOption {
+	return func(o *clientOptions) error {
+		o.insecure = true
+		return nil
+	}
+}
+
+// WithProxy sends the requests to the OVC through the HTTP proxy at proxyURL.
+func WithProxy(proxyURL string) ClientOption {
+	return func(o *clientOptions) error {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+
+		o.proxy = proxy
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests to the OVC.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) error {
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client.
+// A nil policy disables retries.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		o.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the client events.
+func WithLogger(logger Logger) ClientOption {
+	return func(o *clientOptions) error {
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithInterceptors adds interceptors to the requests of the client.
+// The first interceptor is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(o *clientOptions) error {
+		o.interceptors = append(o.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithPageWorkers sets the maximum number of pages fetched concurrently
+// by the GetAllPages methods of the resources.
+func WithPageWorkers(workers int) ClientOption {
+	return func(o *clientOptions) error {
+		o.pageWorkers = workers
+		return nil
+	}
+}
+
+// WithPollPolicy sets how the client polls the tasks until they're done.
+func WithPollPolicy(policy *PollPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		o.pollPolicy = policy
+		return nil
+	}
+}
+
+// WithTaskJournal records the tasks started by the client
+// in the journal file at path, see TaskJournal.
+func WithTaskJournal(path string) ClientOption {
+	return func(o *clientOptions) error {
+		o.taskJournal = NewTaskJournal(path)
+		return nil
+	}
+}
+
+// NewClientWithOptions creates a new OVC client configured by the options.
+// Either WithPassword or WithTokenSource is required.
+// The context controls the initial login request.
+func NewClientWithOptions(ctx context.Context, ovc_ip string, opts ...ClientOption) (*Client, error) {
+	o := &clientOptions{retryPolicy: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		err := opt(o)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.tokenSource == nil && o.username == "" {
+		return nil, errors.New("Pass the OVC credentials or a token source")
+	}
+
+	c := &Client{
+		OVCIP:              ovc_ip,
+		Username:           o.username,
+		Password:           o.password,
+		TokenSource:        o.tokenSource,
+		SSLCertificatePath: o.caCertFile,
+		RetryPolicy:        o.retryPolicy,
+		Logger:             o.logger,
+		Interceptors:       o.interceptors,
+		PageWorkers:        o.pageWorkers,
+		PollPolicy:         o.pollPolicy,
+		TaskJournal:        o.taskJournal,
+		timeout:            o.timeout,
+		userAgent:          o.userAgent,
+	}
+
+	return c.init(ctx, o)
+}
+
+// withTimeout limits the context to the call timeout of the client.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
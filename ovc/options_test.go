@@ -0,0 +1,210 @@
+package ovc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTLSServer() *httptest.Server {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	})
+
+	return httptest.NewTLSServer(apiHandler)
+}
+
+func TestNewClientWithOptionsVerifiesCertificate(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+
+	_, err := NewClientWithOptions(context.Background(), server.URL, WithPassword("user", "pass"))
+	var certErr x509.UnknownAuthorityError
+	if !errors.As(err, &certErr) {
+		t.Errorf("Returned error = %v, expected an unknown authority error", err)
+	}
+
+	_, err = NewClientWithOptions(context.Background(), server.URL, WithPassword("user", "pass"), WithInsecureSkipVerify())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithCACertFile(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ovc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = ioutil.WriteFile(path, caCert, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewClientWithOptions(context.Background(), server.URL, WithPassword("user", "pass"), WithCACertFile(path))
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Legacy constructor with a certificate path
+	_, err = NewClient("user", "pass", server.URL, path)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	_, err := NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "pass"), WithTLSConfig(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithTLSConfigAndCACertFile(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ovc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = ioutil.WriteFile(path, caCert, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	_, err = NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "pass"), WithTLSConfig(&tls.Config{RootCAs: roots}), WithCACertFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The certificates of the file are not added to the pool of the caller
+	if len(roots.Subjects()) != 0 {
+		t.Error("The root CAs of the TLS configuration were changed")
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+
+	_, err := NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "pass"), WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		if r.URL.Host != "ovc.example.com" {
+			t.Errorf("Proxied host = %s, expected ovc.example.com", r.URL.Host)
+		}
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	}))
+	defer proxy.Close()
+
+	_, err := NewClientWithOptions(context.Background(), "http://ovc.example.com",
+		WithPassword("user", "pass"), WithProxy(proxy.URL))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !proxied {
+		t.Error("Request was not sent through the proxy")
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "User-Agent", "test-agent")
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "User-Agent", "test-agent")
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	client, err := NewClientWithOptions(context.Background(), server.URL,
+		WithPassword("user", "pass"), WithUserAgent("test-agent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DoRequest("GET", "/test/path", "", nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	options := []ClientOption{
+		WithRequestTimeout(50 * time.Millisecond),
+		WithTimeout(50 * time.Millisecond),
+	}
+	for _, option := range options {
+		client, err := NewClientWithOptions(context.Background(), server.URL,
+			WithPassword("user", "pass"), WithRetryPolicy(nil), option)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.DoRequest("GET", "/test/path", "", nil, nil)
+		if err == nil {
+			t.Error("Request should time out")
+		}
+	}
+}
+
+func TestNewClientWithOptionsWithoutCredentials(t *testing.T) {
+	_, err := NewClientWithOptions(context.Background(), "ovc_ip")
+	if err == nil {
+		t.Error("Client should require credentials or a token source")
+	}
+}
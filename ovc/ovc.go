@@ -3,25 +3,21 @@ package ovc
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// Logs will be helpful for debugging purpose
-// Comment the init function to get the logs
-func init() {
-	log.SetOutput(ioutil.Discard)
-}
-
 // Http status codes of the OVC API endpoints.
 var (
 	httpStatusCodes = map[int]bool{
@@ -39,10 +35,12 @@ var (
 		http.StatusBadGateway:            false,
 		http.StatusGatewayTimeout:        false,
 	}
-
-	commonClient *Client
 )
 
+// ErrNoClient is returned by the operations of a resource
+// that was not fetched through an OVC client.
+var ErrNoClient = errors.New("Resource is not bound to an OVC client")
+
 // Query Parameters of the Get all endpoints.
 type GetAllParams struct {
 	Limit              int
@@ -56,11 +54,12 @@ type GetAllParams struct {
 }
 
 // QueryString creates query string from the GetAllParams parameters including filters
+// The limit defaults to 500 members, use the Iterate methods of the resources to get all the pages.
 func (p GetAllParams) QueryString() string {
 	QueryStr := url.Values{}
 
 	if p.Limit < 1 {
-		p.Limit = 500
+		p.Limit = defaultPageLimit
 	}
 	QueryStr.Add("limit", strconv.Itoa(p.Limit))
 	QueryStr.Add("offset", strconv.Itoa(p.Offset))
@@ -84,6 +83,10 @@ func (p GetAllParams) QueryString() string {
 type Client struct {
 	client *http.Client
 	common resourceClient // Common OVC client for all the resources
+	tokens tokenManager   // Access token shared by concurrent requests
+
+	timeout   time.Duration // Time limit of the calls to the OVC
+	userAgent string        // User-Agent header of the requests
 
 	// OVC IP
 	OVCIP string
@@ -94,12 +97,41 @@ type Client struct {
 	// OVC password
 	Password string
 
+	// Source of the access tokens
+	// The password grant with Username and Password is used if not set.
+	TokenSource TokenSource
+
 	// OVC access token
+	// Use GetAccessToken to read the token while requests are running.
 	AccessToken string
 
 	// SSL certificate path
 	SSLCertificatePath string
 
+	// Logger of the client events
+	// Events are dropped if not set.
+	Logger Logger
+
+	// Retry policy of the requests to the OVC
+	// New clients use DefaultRetryPolicy. Set to nil to disable retries.
+	RetryPolicy *RetryPolicy
+
+	// Poll policy of the tasks
+	// DefaultPollPolicy is used if not set.
+	PollPolicy *PollPolicy
+
+	// Journal of the tasks started by the client
+	// Tasks are not recorded if not set.
+	TaskJournal *TaskJournal
+
+	// Maximum number of pages fetched concurrently by the GetAllPages methods
+	// Defaults to 4.
+	PageWorkers int
+
+	// Interceptors of the requests to the OVC
+	// The first interceptor is the outermost one.
+	Interceptors []Interceptor
+
 	//OVC resource clients
 	Backups           *BackupResource
 	Datastores        *DatastoreResource
@@ -116,31 +148,42 @@ type resourceClient struct {
 	client *Client
 }
 
-// Auth token endpoint response.
-type auth struct {
-	AccessToken string `json:"access_token,omitempty"`
-}
-
 // NewClient creates a new OVC client.
 // Sets http client to make http connection with the OVC.
 // Sets access token by communicating with the auth token endpoint.
 // Initializes resource clients with a common OVC client.
+// The OVC certificate is verified with the system root certificates
+// and the certificate at the ssl_certificate path, if set.
 func NewClient(username string, password string, ovc_ip string, ssl_certificate string) (*Client, error) {
-	c := &Client{Username: username, Password: password, OVCIP: ovc_ip, SSLCertificatePath: ssl_certificate}
+	return NewClientWithContext(context.Background(), username, password, ovc_ip, ssl_certificate)
+}
+
+// NewClientWithContext creates a new OVC client like NewClient.
+// The context controls the initial login request.
+func NewClientWithContext(ctx context.Context, username string, password string, ovc_ip string, ssl_certificate string) (*Client, error) {
+	return NewClientWithOptions(ctx, ovc_ip, WithPassword(username, password), WithCACertFile(ssl_certificate))
+}
+
+// NewClientWithTokenSource creates a new OVC client which gets
+// its access tokens from the token source instead of logging in
+// with a username and password.
+func NewClientWithTokenSource(src TokenSource, ovc_ip string, ssl_certificate string) (*Client, error) {
+	return NewClientWithOptions(context.Background(), ovc_ip, WithTokenSource(src), WithCACertFile(ssl_certificate))
+}
+
+// init sets the http client, the access token and the resource clients.
+func (c *Client) init(ctx context.Context, o *clientOptions) (*Client, error) {
 	c.common.client = c
 
 	// Set Http client.
-	err := c.setHttpClient()
+	err := c.setHttpClient(o)
 	if err != nil {
 		return nil, err
 	}
 
-	commonClient = c
-
-	// Login and get access token using the username and password.
-	err = c.SetAccessToken()
+	// Get access token from the token source.
+	err = c.SetAccessTokenWithContext(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -158,30 +201,55 @@ func NewClient(username string, password string, ovc_ip string, ssl_certificate
 }
 
 // setHttpClient creates a http client to communicate with the OVC.
-// A secured connection will be established, if a SSL certificate file path exists.
-func (c *Client) setHttpClient() error {
+// The OVC certificate is verified unless the insecure option is set.
+func (c *Client) setHttpClient(o *clientOptions) error {
+	if o.httpClient != nil {
+		c.client = o.httpClient
+		return nil
+	}
 
-	var tr *http.Transport
+	tlsConfig := &tls.Config{}
+	if o.tlsConfig != nil {
+		tlsConfig = o.tlsConfig.Clone()
+	}
 
-	if c.SSLCertificatePath != "" {
-		caCert, err := ioutil.ReadFile(c.SSLCertificatePath)
+	if o.caCertFile != "" {
+		caCert, err := ioutil.ReadFile(o.caCertFile)
 		if err != nil {
-			//log.Fatal(err)
 			return err
 		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
 
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
+		// The pool of the TLS configuration belongs to the caller
+		var caCertPool *x509.CertPool
+		if tlsConfig.RootCAs != nil {
+			caCertPool, err = copyCertPool(tlsConfig.RootCAs)
+			if err != nil {
+				return err
+			}
+		} else {
+			caCertPool, err = x509.SystemCertPool()
+			if err != nil {
+				caCertPool = x509.NewCertPool()
+			}
+		}
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("No certificates found in %s", o.caCertFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if o.insecure {
+		tlsConfig.InsecureSkipVerify = true
+	}
 
-	} else {
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConfig
+	if o.proxy != nil {
+		tr.Proxy = http.ProxyURL(o.proxy)
 	}
 
 	//Set Http client to make requests to OVC
-	c.client = &http.Client{Transport: tr}
+	c.client = &http.Client{Transport: tr, Timeout: o.requestTimeout}
 
 	return nil
 }
@@ -208,60 +276,59 @@ func (c *Client) CreateResourceURL(path string, query string) (*url.URL, error)
 }
 
 // SetAccessToken sets the access token which will be used by other endpoints
-// Makes a call to the login endpoint and gets the token.
+// Gets the token from the token source of the client, by default
+// a call to the login endpoint with the username and password.
 func (c *Client) SetAccessToken() error {
-	endpoint, err := c.CreateResourceURL("/oauth/token", "")
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	//Sets form data of the login API
-	reqData := url.Values{}
-	reqData.Set("username", c.Username)
-	reqData.Set("password", c.Password)
-	reqData.Set("grant_type", "password")
-
-	//Creates new http request with URL encoded body
-	req, err := http.NewRequest("POST", endpoint.String(), bytes.NewBufferString(reqData.Encode()))
-	if err != nil {
-		return err
-	}
+	return c.SetAccessTokenWithContext(context.Background())
+}
 
-	//Login API expects URL encoded body
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+// SetAccessTokenWithContext sets the access token like SetAccessToken.
+// The context controls the login request.
+func (c *Client) SetAccessTokenWithContext(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
-	//Basic auth should be set with username simplivity and empty password
-	req.SetBasicAuth("simplivity", "")
+	c.tokens.refreshMu.Lock()
+	defer c.tokens.refreshMu.Unlock()
 
-	//Makes request to the login API
-	data, err, httpError := c.Do(req)
-	if err != nil {
-		return err
-	}
+	return c.login(ctx)
+}
 
-	// Handles http errors.
-	if httpError != nil {
-		return fmt.Errorf("Error: Status code:%s - %s", httpError.Status, httpError.Message)
+// login gets a new access token from the token source of the client.
+// Callers must hold the refresh lock of the token manager.
+func (c *Client) login(ctx context.Context) error {
+	src := c.TokenSource
+	if src == nil {
+		src = &PasswordTokenSource{Username: c.Username, Password: c.Password}
 	}
 
-	//Unmarshal the login API response to get the token
-	authData := auth{}
-	err = json.Unmarshal(data, &authData)
+	token, err := src.Token(ctx, c)
 	if err != nil {
+		c.log().Error("Getting an access token failed", "error", err)
 		return err
 	}
 
-	//Set access token
-	c.AccessToken = authData.AccessToken
+	c.log().Info("Access token refreshed", "expiry", token.Expiry)
+
+	//Set access token and its expiry
+	c.setToken(token)
 
 	return nil
 }
 
 // SetHeaders sets headers of the API requests.
 func (c *Client) SetHeaders(req *http.Request, headers map[string]string) {
+	c.setHeaders(req, c.GetAccessToken(), headers)
+}
+
+// setHeaders sets headers of the API requests using the given access token.
+func (c *Client) setHeaders(req *http.Request, token string, headers map[string]string) {
 	//Required for all the API requests except login API
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	//Set other headers of the API request
 	for name, value := range headers {
@@ -272,13 +339,22 @@ func (c *Client) SetHeaders(req *http.Request, headers map[string]string) {
 // DoRequest creates a new http request and make calls to the OVC.
 // Creates a new http request using NewRequest method.
 // Makes http call to the OVC using Do method.
-// Tries to get another token and makes a fresh request if the token expired.
+// Retries transient failures according to the retry policy of the client.
+// Refreshes the token before it expires, or when the OVC reports it as invalid,
+// and makes a fresh request with the new token.
+// Safe for concurrent use.
 func (c *Client) DoRequest(method, path, queryStr string, body interface{}, headers map[string]string) ([]byte, error) {
-	var data []byte
+	return c.DoRequestWithContext(context.Background(), method, path, queryStr, body, headers)
+}
 
-	req, err := c.NewRequest(method, path, queryStr, body)
+// DoRequestWithContext makes calls to the OVC like DoRequest.
+// Cancelling the context aborts the in-flight request and any token refresh.
+func (c *Client) DoRequestWithContext(ctx context.Context, method, path, queryStr string, body interface{}, headers map[string]string) ([]byte, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	token, err := c.accessToken(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -294,22 +370,35 @@ func (c *Client) DoRequest(method, path, queryStr string, body interface{}, head
 		}
 	}
 
-	c.SetHeaders(req, req_headers)
-	data, err, httpError := c.Do(req)
+	// Makes a fresh request for every attempt, as the body of a sent request is consumed.
+	call := func() ([]byte, error) {
+		req, err := c.NewRequestWithContext(ctx, method, path, queryStr, body)
+		if err != nil {
+			return nil, err
+		}
+
+		c.setHeaders(req, token, req_headers)
+		return c.send(req)
+	}
+
+	data, err := c.retry(ctx, method, call)
 
 	// Get a fresh token and make a new request if the current token is expired.
-	if httpError != nil {
-		if httpError.Error == "invalid_token" {
-			log.Println("Token expired - trying to make another call with a new token")
-			c.SetAccessToken()
-			c.SetHeaders(req, req_headers)
-			data, err, httpError = c.Do(req)
-			if httpError != nil {
-				err = fmt.Errorf("Error: Status code:%s - %s", httpError.Status, httpError.Message)
-			}
-			return data, err
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorType == "invalid_token" {
+		c.log().Info("Access token expired, trying to make another call with a new token", "method", method, "path", path)
+		stale := token
+		token, err = c.refreshToken(ctx, stale)
+		if err != nil {
+			return nil, err
+		}
+
+		// The token source has no other token to offer.
+		if token == stale {
+			return nil, apiErr
 		}
-		err = fmt.Errorf("Error: Status code:%s - %s", httpError.Status, httpError.Message)
+
+		data, err = c.retry(ctx, method, call)
 	}
 
 	return data, err
@@ -317,9 +406,15 @@ func (c *Client) DoRequest(method, path, queryStr string, body interface{}, head
 
 // NewRequest creates a new http request by setting the URL, method and body.
 func (c *Client) NewRequest(method, path, query string, body interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, path, query, body)
+}
+
+// NewRequestWithContext creates a new http request like NewRequest.
+// The context is attached to the request and cancels it when done.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, path, query string, body interface{}) (*http.Request, error) {
 	resourceURL, err := c.CreateResourceURL(path, query)
 	if err != nil {
-		log.Println(err)
+		return nil, err
 	}
 
 	var buf io.ReadWriter
@@ -333,7 +428,7 @@ func (c *Client) NewRequest(method, path, query string, body interface{}) (*http
 		}
 	}
 
-	req, err := http.NewRequest(method, resourceURL.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, resourceURL.String(), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -352,31 +447,61 @@ type OVCRespError struct {
 
 // Do makes calls to the OVC
 // Returns data if the call is successfull or error
+// The call is bound to the context of the request, see NewRequestWithContext.
 func (c *Client) Do(req *http.Request) ([]byte, error, *OVCRespError) {
+	data, err := c.send(req)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return nil, nil, apiErr.respError()
+	}
+
+	return data, err, nil
+}
+
+// send makes calls to the OVC like Do, through the interceptors of the client.
+// Error responses of the OVC are returned as *APIError.
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	return c.chain(c.invoke)(req)
+}
+
+// invoke makes a call to the OVC without the interceptors.
+func (c *Client) invoke(req *http.Request) ([]byte, error) {
+	c.logRequest(req)
+	start := time.Now()
+
 	resp, err := c.client.Do(req)
 
 	if err != nil {
-		return nil, err, nil
+		c.log().Warn("OVC request failed", "method", req.Method, "url", req.URL.String(), "error", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err, nil
+		c.log().Warn("OVC response could not be read", "method", req.Method, "url", req.URL.String(), "error", err)
+		return nil, err
 	}
 
+	c.log().Debug("OVC response", "method", req.Method, "url", req.URL.String(),
+		"status", resp.StatusCode, "duration", time.Since(start))
+
 	if !httpStatusCodes[resp.StatusCode] {
-		log.Println("HTTP status code", resp.StatusCode)
 		var errResp OVCRespError
 
+		// Gateways may respond with a body which is not JSON
 		err = json.Unmarshal(data, &errResp)
 		if err != nil {
-			return nil, err, nil
-			log.Println("Unmarshal error", err)
+			errResp.Message = http.StatusText(resp.StatusCode)
 		}
 
-		return nil, nil, &errResp
+		apiErr := newAPIError(req, resp.StatusCode, &errResp)
+		c.log().Warn("OVC error response", "method", req.Method, "url", req.URL.String(),
+			"status", resp.StatusCode, "error", apiErr.ErrorType, "message", apiErr.Message)
+
+		return nil, apiErr
 	}
 
-	return data, nil, nil
+	return data, nil
 }
@@ -1,7 +1,9 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -9,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func setup() (client *Client, mux *http.ServeMux, teardown func()) {
@@ -26,6 +29,11 @@ func setup() (client *Client, mux *http.ServeMux, teardown func()) {
 		fmt.Println("Test setup Error", err)
 	}
 
+	// Poll the tasks without slowing down the tests.
+	if client != nil {
+		client.PollPolicy = &PollPolicy{InitialInterval: time.Millisecond}
+	}
+
 	return client, apiHandler, server.Close
 }
 
@@ -127,9 +135,14 @@ func TestDoRequestTokenExpiry(t *testing.T) {
 	})
 
 	_, err := client.DoRequest("POST", "/test/path", "", "", headers)
-	errorMessage := "Error: Status code: - Access token expired due to inactivity: 4a1da31f-5405-4a93-af5c-799403ea70d6"
-	if err != nil && err.Error() != errorMessage {
-		t.Error("Call should return invalid_token error")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorType != "invalid_token" {
+		t.Fatal("Call should return invalid_token error")
+	}
+
+	errorMessage := "Access token expired due to inactivity: 4a1da31f-5405-4a93-af5c-799403ea70d6"
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != errorMessage {
+		t.Errorf("Returned = %v, expected status code 401 and message %s", apiErr, errorMessage)
 	}
 }
 
@@ -141,3 +154,84 @@ func TestCommonOVCClient(t *testing.T) {
 		t.Errorf("Resource clients are not using a common OVC client.")
 	}
 }
+
+func TestDoRequestWithContextCancelled(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Test":"a"}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.DoRequestWithContext(ctx, "GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Returned error = %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestMultipleClients(t *testing.T) {
+	newServer := func(token string, hits *int) (*Client, func()) {
+		apiHandler := http.NewServeMux()
+		apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"access_token": "%s"}`, token)
+		})
+		apiHandler.HandleFunc("/virtual_machines", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"offset": 0, "limit": 500, "count": 1, "virtual_machines":[{"name": "testvm", "id":"1"}]}`)
+		})
+		apiHandler.HandleFunc("/virtual_machines/1/set_policy", func(w http.ResponseWriter, r *http.Request) {
+			testRequestHeader(t, r, "Authorization", "Bearer "+token)
+			*hits++
+			fmt.Fprint(w, `{"task":{"state": "COMPLETE", "id": "1", "affected_objects":[{"object_id":"1"}]}}`)
+		})
+
+		server := httptest.NewServer(apiHandler)
+		client, err := NewClient("user", "pass", server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client, server.Close
+	}
+
+	var hitsA, hitsB int
+	clientA, teardownA := newServer("token-a", &hitsA)
+	defer teardownA()
+
+	vmA, err := clientA.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Creating another client must not rebind resources fetched with the first one.
+	clientB, teardownB := newServer("token-b", &hitsB)
+	defer teardownB()
+
+	vmB, err := clientB.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &Policy{Id: "1"}
+	if err = vmA.SetPolicy(policy); err != nil {
+		t.Error(err)
+	}
+	if err = vmA.SetPolicy(policy); err != nil {
+		t.Error(err)
+	}
+	if err = vmB.SetPolicy(policy); err != nil {
+		t.Error(err)
+	}
+
+	if hitsA != 2 || hitsB != 1 {
+		t.Errorf("Requests per OVC = %d, %d, expected 2, 1", hitsA, hitsB)
+	}
+}
+
+func TestUnboundResource(t *testing.T) {
+	vm := &VirtualMachine{Id: "1"}
+	if err := vm.SetPolicy(&Policy{Id: "1"}); err != ErrNoClient {
+		t.Errorf("Returned error = %v, expected %v", err, ErrNoClient)
+	}
+}
@@ -0,0 +1,156 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStopIteration can be returned by the callback of an Iterate method
+// to stop the iteration early. Iterate then returns nil.
+var ErrStopIteration = errors.New("Stop iteration")
+
+// ErrCountChanged is returned by the GetAllPages methods when the number
+// of members of a list endpoint keeps changing while its pages are fetched.
+var ErrCountChanged = errors.New("Number of members changed while fetching the pages")
+
+// ErrShortPage is returned by the GetAllPages methods when a page
+// has fewer members than the page size of the first page, or than the
+// members left for the last page.
+var ErrShortPage = errors.New("Page has fewer members than the page size")
+
+const (
+	// Number of members in a page when GetAllParams.Limit is not set
+	defaultPageLimit = 500
+
+	// Number of pages fetched concurrently when Client.PageWorkers is not set
+	defaultPageWorkers = 4
+
+	// Number of times the pages are fetched again after the number of members changed
+	maxPageRefetches = 2
+)
+
+// paginate walks the pages of a list endpoint, starting at params.Offset.
+// fetch gets the page at the offset of its params, passes the members
+// to the caller and returns the total number of members and the number
+// of members in the page. The walk stops after the last page,
+// or when fetch returns an error.
+func paginate(params GetAllParams, fetch func(params GetAllParams) (count int, size int, err error)) error {
+	if params.Limit < 1 {
+		params.Limit = defaultPageLimit
+	}
+
+	for {
+		count, size, err := fetch(params)
+		if errors.Is(err, ErrStopIteration) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		params.Offset += size
+		if size == 0 || params.Offset >= count {
+			return nil
+		}
+	}
+}
+
+// fetchAllPages gets all the pages of a list endpoint, starting at params.Offset.
+// The first page gives the number of members and the page size, which is less
+// than params.Limit when the OVC caps it. The other pages are then fetched
+// concurrently by at most workers goroutines. All the pages are fetched again
+// if the number of members changes in the meantime.
+// fetch gets the page with the given index at the offset of its params,
+// stores its members and returns the number of members and the number
+// of members in the page. The first page is always fetched alone,
+// before the others.
+func fetchAllPages(ctx context.Context, params GetAllParams, workers int, fetch func(ctx context.Context, page int, params GetAllParams) (count int, size int, err error)) (int, error) {
+	if params.Limit < 1 {
+		params.Limit = defaultPageLimit
+	}
+	if workers < 1 {
+		workers = defaultPageWorkers
+	}
+
+	for refetch := 0; ; refetch++ {
+		count, size, err := fetch(ctx, 0, params)
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 {
+			return count, nil
+		}
+
+		pageParams := params
+		pageParams.Limit = size
+		err = fetchOtherPages(ctx, pageParams, count, workers, fetch)
+		if errors.Is(err, ErrCountChanged) && refetch < maxPageRefetches {
+			continue
+		}
+
+		return count, err
+	}
+}
+
+// fetchOtherPages gets the pages of params.Limit members after the first one
+// with a pool of workers. The first error cancels the other requests.
+func fetchOtherPages(ctx context.Context, params GetAllParams, count int, workers int, fetch func(ctx context.Context, page int, params GetAllParams) (int, int, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		pages    = make(chan int)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				pageParams := params
+				pageParams.Offset = params.Offset + page*params.Limit
+
+				// Every page but the last one is full
+				expected := params.Limit
+				if left := count - pageParams.Offset; left < expected {
+					expected = left
+				}
+
+				pageCount, size, err := fetch(ctx, page, pageParams)
+				if err == nil && pageCount != count {
+					err = fmt.Errorf("%w: %d, then %d", ErrCountChanged, count, pageCount)
+				}
+				if err == nil && size < expected {
+					err = fmt.Errorf("%w: %d members at offset %d, expected %d", ErrShortPage, size, pageParams.Offset, expected)
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+send:
+	for offset, page := params.Offset+params.Limit, 1; offset < count; offset, page = offset+params.Limit, page+1 {
+		select {
+		case pages <- page:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(pages)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,237 @@
+package ovc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mockPages serves count members of a list endpoint page by page.
+// Returns the offsets of the requested pages.
+func mockPages(apiHandler *http.ServeMux, path string, name string, count int) *[]int {
+	return mockCappedPages(apiHandler, path, name, count, 0)
+}
+
+// mockCappedPages is like mockPages but serves at most maxLimit members
+// in a page, whatever the requested limit, like the OVC.
+func mockCappedPages(apiHandler *http.ServeMux, path string, name string, count int, maxLimit int) *[]int {
+	var mu sync.Mutex
+	offsets := []int{}
+	apiHandler.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if maxLimit > 0 && limit > maxLimit {
+			limit = maxLimit
+		}
+		mu.Lock()
+		offsets = append(offsets, offset)
+		mu.Unlock()
+
+		members := []string{}
+		for i := offset; i < offset+limit && i < count; i++ {
+			members = append(members, fmt.Sprintf(`{"id": "%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"offset": %d, "limit": %d, "count": %d, "%s": [%s]}`,
+			offset, limit, count, name, strings.Join(members, ","))
+	})
+
+	return &offsets
+}
+
+func TestIterate(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	offsets := mockPages(apiHandler, "/virtual_machines", "virtual_machines", 5)
+
+	ids := []string{}
+	err := client.VirtualMachines.Iterate(GetAllParams{Limit: 2}, func(vm *VirtualMachine) error {
+		ids = append(ids, vm.Id)
+		if vm.client != client {
+			t.Error("VM is not bound to the client")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(ids, ","); got != "0,1,2,3,4" {
+		t.Errorf("Iterated VMs %s, expected 0,1,2,3,4", got)
+	}
+	if got := fmt.Sprint(*offsets); got != "[0 2 4]" {
+		t.Errorf("Requested offsets %s, expected [0 2 4]", got)
+	}
+}
+
+func TestIterateStop(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	offsets := mockPages(apiHandler, "/backups", "backups", 5)
+
+	ids := []string{}
+	err := client.Backups.Iterate(GetAllParams{Limit: 2}, func(backup *Backup) error {
+		ids = append(ids, backup.Id)
+		if backup.Id == "2" {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(ids, ","); got != "0,1,2" {
+		t.Errorf("Iterated backups %s, expected 0,1,2", got)
+	}
+	if got := fmt.Sprint(*offsets); got != "[0 2]" {
+		t.Errorf("Requested offsets %s, expected [0 2]", got)
+	}
+}
+
+func TestGetByAllPages(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockPages(apiHandler, "/datastores", "datastores", 1200)
+
+	datastores, err := client.Datastores.GetBy("name", "datastore*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(datastores) != 1200 {
+		t.Errorf("Returned %d datastores, expected 1200", len(datastores))
+	}
+}
+
+func TestGetAllPages(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	client.PageWorkers = 3
+	offsets := mockPages(apiHandler, "/virtual_machines", "virtual_machines", 95)
+
+	vmList, err := client.VirtualMachines.GetAllPages(GetAllParams{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vmList.Count != 95 || len(vmList.Members) != 95 {
+		t.Fatalf("Returned %d of %d VMs, expected 95", len(vmList.Members), vmList.Count)
+	}
+	for i, vm := range vmList.Members {
+		if vm.Id != strconv.Itoa(i) {
+			t.Fatalf("VM %d has id %s, the pages are out of order", i, vm.Id)
+		}
+	}
+	if len(*offsets) != 10 {
+		t.Errorf("Requested %d pages, expected 10", len(*offsets))
+	}
+}
+
+func TestGetAllPagesCappedLimit(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// The OVC returns 2 backups per page whatever the limit
+	offsets := mockCappedPages(apiHandler, "/backups", "backups", 6, 2)
+
+	backupList, err := client.Backups.GetAllPages(GetAllParams{Limit: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{}
+	for _, backup := range backupList.Members {
+		ids = append(ids, backup.Id)
+	}
+	if got := strings.Join(ids, ","); got != "0,1,2,3,4,5" {
+		t.Errorf("Returned backups %s, expected 0,1,2,3,4,5", got)
+	}
+
+	sort.Ints(*offsets)
+	if got := fmt.Sprint(*offsets); got != "[0 2 4]" {
+		t.Errorf("Requested offsets %s, expected [0 2 4]", got)
+	}
+}
+
+func TestGetAllPagesShortPage(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// The page at offset 2 misses a backup
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("offset") {
+		case "2":
+			fmt.Fprint(w, `{"count": 6, "backups": [{"id": "2"}]}`)
+		default:
+			fmt.Fprint(w, `{"count": 6, "backups": [{"id": "0"}, {"id": "1"}]}`)
+		}
+	})
+
+	_, err := client.Backups.GetAllPages(GetAllParams{Limit: 2})
+	if !errors.Is(err, ErrShortPage) {
+		t.Errorf("Returned error = %v, expected ErrShortPage", err)
+	}
+}
+
+func TestGetAllPagesCountChanged(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// Every page reports one more backup than the previous one.
+	var mu sync.Mutex
+	count := 10
+	apiHandler.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		fmt.Fprintf(w, `{"count": %d, "backups": [{"id": "1"}]}`, count)
+	})
+
+	_, err := client.Backups.GetAllPages(GetAllParams{Limit: 1})
+	if !errors.Is(err, ErrCountChanged) {
+		t.Errorf("Returned error = %v, expected ErrCountChanged", err)
+	}
+}
+
+func TestGetAllPagesRefetch(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// A host is added after the first page.
+	var mu sync.Mutex
+	requests := 0
+	apiHandler.HandleFunc("/hosts", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		count := 4
+		if requests > 1 {
+			count = 5
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		members := []string{}
+		for i := offset; i < offset+2 && i < count; i++ {
+			members = append(members, fmt.Sprintf(`{"id": "%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"count": %d, "hosts": [%s]}`, count, strings.Join(members, ","))
+	})
+
+	hostList, err := client.Hosts.GetAllPages(GetAllParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hostList.Count != 5 || len(hostList.Members) != 5 {
+		t.Errorf("Returned %d of %d hosts, expected 5", len(hostList.Members), hostList.Count)
+	}
+}
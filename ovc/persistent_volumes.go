@@ -1,10 +1,12 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"sync"
+	"time"
 )
 
 // Support for persistent_volume is added in v1.16 in SimpliVity
@@ -49,9 +51,61 @@ type PersistentVolume struct {
 	ComputeClusterParentName               string           `json:"compute_cluster_parent_name,omitempty"`
 	ClusterGroupIds                        []string         `json:"cluster_group_ids,omitempty"`
 	ReplicaSet                             []ReplicaSetList `json:"replica_set,omitempty"`
+
+	// OVC client used for the PV operations
+	client *Client
+}
+
+// PersistentVolumeFilter is a typed filter of the persistent volumes.
+// See GetAll for the meaning of the fields.
+type PersistentVolumeFilter struct {
+	Ids                                     []string
+	Names                                   []string
+	OmniStackClusterIds                     []string
+	OmniStackClusterNames                   []string
+	ComputeClusterParentHypervisorObjectIds []string
+	ComputeClusterParentNames               []string
+	HypervisorManagementSystems             []string
+	HypervisorManagementSystemNames         []string
+	DatastoreIds                            []string
+	DatastoreNames                          []string
+	PolicyIds                               []string
+	PolicyNames                             []string
+	HypervisorObjectIds                     []string
+	CreatedAfter                            time.Time
+	CreatedBefore                           time.Time
+	States                                  []string
+	AppAwareVMStatuses                      []string
+	HostId                                  string
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f PersistentVolumeFilter) Filters() map[string]string {
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.list("omnistack_cluster_id", f.OmniStackClusterIds)
+	values.list("omnistack_cluster_name", f.OmniStackClusterNames)
+	values.list("compute_cluster_parent_hypervisor_object_id", f.ComputeClusterParentHypervisorObjectIds)
+	values.list("compute_cluster_parent_name", f.ComputeClusterParentNames)
+	values.list("hypervisor_management_system", f.HypervisorManagementSystems)
+	values.list("hypervisor_management_system_name", f.HypervisorManagementSystemNames)
+	values.list("datastore_id", f.DatastoreIds)
+	values.list("datastore_name", f.DatastoreNames)
+	values.list("policy_id", f.PolicyIds)
+	values.list("policy_name", f.PolicyNames)
+	values.list("hypervisor_object_id", f.HypervisorObjectIds)
+	values.time("created_after", f.CreatedAfter)
+	values.time("created_before", f.CreatedBefore)
+	values.list("state", f.States)
+	values.list("app_aware_vm_status", f.AppAwareVMStatuses)
+	values.string("host_id", f.HostId)
+
+	return values
 }
 
 // GetAll returns all the persistent volumes filtered by the query parameters.
+// Build the filters with PersistentVolumeFilter.
 // Filters:
 //   id: The unique identifier (UID) of the persistent volume to return
 //     Accepts: Single value, comma-separated list
@@ -104,13 +158,18 @@ type PersistentVolume struct {
 //     Accepts: Single value, comma-separated list
 //   host_id: The unique identifier (UID) of the persistent_volume host.
 func (p *PersistentVolumeResource) GetAll(params GetAllParams) (*PersistentVolumeList, error) {
+	return p.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*PersistentVolumeList, error) {
 	var (
 		path   = "/persistent_volumes"
 		pvList PersistentVolumeList
 	)
 
 	qrStr := params.QueryString()
-	resp, err := p.client.DoRequest("GET", path, qrStr, nil, header)
+	resp, err := p.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, header)
 	if err != nil {
 		return &pvList, err
 	}
@@ -120,28 +179,114 @@ func (p *PersistentVolumeResource) GetAll(params GetAllParams) (*PersistentVolum
 		return &pvList, err
 	}
 
+	for _, pv := range pvList.Members {
+		pv.client = p.client
+	}
+
 	return &pvList, nil
 }
 
+// GetAllPages returns all the persistent volumes matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of persistent volumes keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (p *PersistentVolumeResource) GetAllPages(params GetAllParams) (*PersistentVolumeList, error) {
+	return p.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*PersistentVolumeList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*PersistentVolume
+	)
+
+	count, err := fetchAllPages(ctx, params, p.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		pvList, err := p.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*PersistentVolume{}
+		}
+		pages[page] = pvList.Members
+
+		return pvList.Count, len(pvList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pvList := &PersistentVolumeList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		pvList.Members = append(pvList.Members, pages[page]...)
+	}
+
+	return pvList, nil
+}
+
+// Iterate calls fn with every persistent volume matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (p *PersistentVolumeResource) Iterate(params GetAllParams, fn func(pv *PersistentVolume) error) error {
+	return p.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(pv *PersistentVolume) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		pvList, err := p.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, pv := range pvList.Members {
+			err = fn(pv)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return pvList.Count, len(pvList.Members), nil
+	})
+}
+
 // GetBy searches for PV resources with single filter.
 func (p *PersistentVolumeResource) GetBy(fieldName string, value string) ([]*PersistentVolume, error) {
+	return p.GetByWithContext(context.Background(), fieldName, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) GetByWithContext(ctx context.Context, fieldName string, value string) ([]*PersistentVolume, error) {
+	var pvs []*PersistentVolume
 	filters := map[string]string{fieldName: value}
-	pvList, err := p.GetAll(GetAllParams{Filters: filters})
+	err := p.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(pv *PersistentVolume) error {
+		pvs = append(pvs, pv)
+		return nil
+	})
+
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	pvs := pvList.Members
 	return pvs, nil
 }
 
 // GetByName searches for a PV by its name
 func (p *PersistentVolumeResource) GetByName(name string) (*PersistentVolume, error) {
-	pvs, err := p.GetBy("name", name)
+	return p.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) GetByNameWithContext(ctx context.Context, name string) (*PersistentVolume, error) {
+	pvs, err := p.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -150,15 +295,19 @@ func (p *PersistentVolumeResource) GetByName(name string) (*PersistentVolume, er
 		return pv, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a PV by its id
 func (p *PersistentVolumeResource) GetById(id string) (*PersistentVolume, error) {
-	pvs, err := p.GetBy("id", id)
+	return p.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) GetByIdWithContext(ctx context.Context, id string) (*PersistentVolume, error) {
+	pvs, err := p.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -167,14 +316,39 @@ func (p *PersistentVolumeResource) GetById(id string) (*PersistentVolume, error)
 		return pv, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // SetPolicyForMultiplePVs sets a policy for list of PV resources.
 func (p *PersistentVolumeResource) SetPolicyForMultiplePVs(policy *Policy, pvs []*PersistentVolume) error {
+	return p.SetPolicyForMultiplePVsWithContext(context.Background(), policy, pvs)
+}
+
+// SetPolicyForMultiplePVsWithContext is like SetPolicyForMultiplePVs but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) SetPolicyForMultiplePVsWithContext(ctx context.Context, policy *Policy, pvs []*PersistentVolume) error {
+	task, err := p.SetPolicyForMultiplePVsAsyncWithContext(ctx, policy, pvs)
+	if err != nil {
+		return err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetPolicyForMultiplePVsAsync starts setting the policy of the PVs and returns the task right away.
+func (p *PersistentVolumeResource) SetPolicyForMultiplePVsAsync(policy *Policy, pvs []*PersistentVolume) (*Task, error) {
+	return p.SetPolicyForMultiplePVsAsyncWithContext(context.Background(), policy, pvs)
+}
+
+// SetPolicyForMultiplePVsAsyncWithContext is like SetPolicyForMultiplePVsAsync but uses ctx for the requests to the OVC.
+func (p *PersistentVolumeResource) SetPolicyForMultiplePVsAsyncWithContext(ctx context.Context, policy *Policy, pvs []*PersistentVolume) (*Task, error) {
 	path := fmt.Sprintf("/persistent_volumes/set_policy")
 	if len(pvs) < 1 {
-		return errors.New("Pass a list of PV resoures")
+		return nil, errors.New("Pass a list of PV resoures")
 	}
 
 	pv_ids := []string{}
@@ -183,54 +357,79 @@ func (p *PersistentVolumeResource) SetPolicyForMultiplePVs(policy *Policy, pvs [
 	}
 
 	body := map[string]interface{}{"policy_id": policy.Id, "persistent_volume_id": pv_ids}
-	resp, err := p.client.DoRequest("POST", path, "", body, header)
+	resp, err := p.client.DoRequestWithContext(ctx, "POST", path, "", body, header)
 	if err != nil {
-		return err
-	}
-
-	_, err = commonClient.Tasks.WaitForTask(resp)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return p.client.startTask(resp, "PersistentVolumes.SetPolicyForMultiplePVs", "")
 }
 
 // CreateBackup creates a backup of the PV.
 func (p *PersistentVolume) CreateBackup(req *CreateBackupRequest, dest *OmniStackCluster) (*Backup, error) {
-	path := fmt.Sprintf("/persistent_volumes/%s/backup", p.Id)
-	if dest != nil {
-		req.Destination = dest.Id
-	}
+	return p.CreateBackupWithContext(context.Background(), req, dest)
+}
 
-	resp, err := commonClient.DoRequest("POST", path, "", req, header)
+// CreateBackupWithContext is like CreateBackup but uses ctx for the requests to the OVC.
+func (p *PersistentVolume) CreateBackupWithContext(ctx context.Context, req *CreateBackupRequest, dest *OmniStackCluster) (*Backup, error) {
+	task, err := p.CreateBackupAsyncWithContext(ctx, req, dest)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
 	resources := task.AffectedResources
 	if len(resources) < 1 {
-		err_message := "Backup was not successful. Error code:" + string(task.ErrorCode)
-		return nil, errors.New(err_message)
+		return nil, newTaskError(task)
 	}
 
 	resource_id := resources[0].ObjectId
-	backup, err := commonClient.Backups.GetById(resource_id)
-	return backup, nil
+	backup, err := p.client.Backups.GetByIdWithContext(ctx, resource_id)
+	return backup, err
+}
+
+// CreateBackupAsync starts a backup of the PV and returns the task right away.
+// The id of the backup is the first affected object of the finished task.
+func (p *PersistentVolume) CreateBackupAsync(req *CreateBackupRequest, dest *OmniStackCluster) (*Task, error) {
+	return p.CreateBackupAsyncWithContext(context.Background(), req, dest)
+}
+
+// CreateBackupAsyncWithContext is like CreateBackupAsync but uses ctx for the requests to the OVC.
+func (p *PersistentVolume) CreateBackupAsyncWithContext(ctx context.Context, req *CreateBackupRequest, dest *OmniStackCluster) (*Task, error) {
+	if p.client == nil {
+		return nil, ErrNoClient
+	}
+
+	path := fmt.Sprintf("/persistent_volumes/%s/backup", p.Id)
+	if dest != nil {
+		req.Destination = dest.Id
+	}
+
+	resp, err := p.client.DoRequestWithContext(ctx, "POST", path, "", req, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.client.startTask(resp, "PersistentVolume.CreateBackup", p.Id)
 }
 
 // GetBackups gets all the backups of a PV.
 func (p *PersistentVolume) GetBackups() (*BackupList, error) {
-	backupList, err := commonClient.Backups.GetAll(GetAllParams{Filters: map[string]string{"pv": p.Name}})
+	return p.GetBackupsWithContext(context.Background())
+}
+
+// GetBackupsWithContext is like GetBackups but uses ctx for the requests to the OVC.
+func (p *PersistentVolume) GetBackupsWithContext(ctx context.Context) (*BackupList, error) {
+	if p.client == nil {
+		return nil, ErrNoClient
+	}
+
+	backupList, err := p.client.Backups.GetAllWithContext(ctx, GetAllParams{Filters: map[string]string{"pv": p.Name}})
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -34,7 +34,7 @@ func TestPVGetAllWithDefaultParameters(t *testing.T) {
 		t.Error(err)
 	}
 
-	pv := &PersistentVolume{Name: "pvc-123_fcd", Id: "1"}
+	pv := &PersistentVolume{Name: "pvc-123_fcd", Id: "1", client: client}
 	expected := &PersistentVolumeList{Offset: 1,
 		Limit:   1,
 		Count:   1,
@@ -195,7 +195,7 @@ func TestPVCreateBackup(t *testing.T) {
 		t.Error(err)
 	}
 
-	expected := &Backup{Name: "backup_name", Id: "1"}
+	expected := &Backup{Name: "backup_name", Id: "1", client: client}
 	if !reflect.DeepEqual(backup, expected) {
 		t.Errorf("Returned = %v, expected %v", backup, expected)
 	}
@@ -222,7 +222,7 @@ func TestPVGetBackups(t *testing.T) {
 		t.Error(err)
 	}
 
-	backup := &Backup{Name: "backup_name", Id: "1"}
+	backup := &Backup{Name: "backup_name", Id: "1", client: client}
 	expected := &BackupList{Offset: 1,
 		Limit:   500,
 		Count:   1,
@@ -1,9 +1,9 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"log"
+	"sync"
 )
 
 // PolicyResource handles communications with the the Policy resource methods
@@ -26,13 +26,35 @@ type Policy struct {
 	Rules           []interface{} `json:"rules,omitempty"`
 }
 
+// PolicyFilter is a typed filter of the policies.
+// See GetAll for the meaning of the fields.
+type PolicyFilter struct {
+	Ids   []string
+	Names []string
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f PolicyFilter) Filters() map[string]string {
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+
+	return values
+}
+
 // GetAll returns all the policies filtered by the query parameters.
+// Build the filters with PolicyFilter.
 // Filters:
 //   id: The unique identifier (UID) of the policy
 //     Accepts: Single value, comma-separated list
 //   name:The name of the policy
 //     Accepts: Single value, comma-separated list
 func (p *PolicyResource) GetAll(params GetAllParams) (*PolicyList, error) {
+	return p.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (p *PolicyResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*PolicyList, error) {
 	var (
 		path       = "/policies"
 		policyList PolicyList
@@ -40,7 +62,7 @@ func (p *PolicyResource) GetAll(params GetAllParams) (*PolicyList, error) {
 
 	qrStr := params.QueryString()
 
-	resp, err := p.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := p.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &policyList, err
 	}
@@ -53,27 +75,107 @@ func (p *PolicyResource) GetAll(params GetAllParams) (*PolicyList, error) {
 	return &policyList, nil
 }
 
+// GetAllPages returns all the policies matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of policies keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (p *PolicyResource) GetAllPages(params GetAllParams) (*PolicyList, error) {
+	return p.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (p *PolicyResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*PolicyList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*Policy
+	)
+
+	count, err := fetchAllPages(ctx, params, p.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		policyList, err := p.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*Policy{}
+		}
+		pages[page] = policyList.Members
+
+		return policyList.Count, len(policyList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policyList := &PolicyList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		policyList.Members = append(policyList.Members, pages[page]...)
+	}
+
+	return policyList, nil
+}
+
+// Iterate calls fn with every policy matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (p *PolicyResource) Iterate(params GetAllParams, fn func(policy *Policy) error) error {
+	return p.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (p *PolicyResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(policy *Policy) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		policyList, err := p.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, policy := range policyList.Members {
+			err = fn(policy)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return policyList.Count, len(policyList.Members), nil
+	})
+}
+
 // GetBy searches for Policies with single filter.
 func (p *PolicyResource) GetBy(field string, value string) ([]*Policy, error) {
+	return p.GetByWithContext(context.Background(), field, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (p *PolicyResource) GetByWithContext(ctx context.Context, field string, value string) ([]*Policy, error) {
+	var policys []*Policy
 	filters := map[string]string{field: value}
-	policyList, err := p.GetAll(GetAllParams{Filters: filters})
+	err := p.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(policy *Policy) error {
+		policys = append(policys, policy)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	policies := policyList.Members
-
-	return policies, nil
+	return policys, nil
 }
 
 // GetByName searches for a Policy resource by its name.
 func (p *PolicyResource) GetByName(name string) (*Policy, error) {
-	policies, err := p.GetBy("name", name)
+	return p.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (p *PolicyResource) GetByNameWithContext(ctx context.Context, name string) (*Policy, error) {
+	policies, err := p.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -82,15 +184,19 @@ func (p *PolicyResource) GetByName(name string) (*Policy, error) {
 		return policy, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a Policy resource by its id.
 func (p *PolicyResource) GetById(id string) (*Policy, error) {
-	policies, err := p.GetBy("id", id)
+	return p.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (p *PolicyResource) GetByIdWithContext(ctx context.Context, id string) (*Policy, error) {
+	policies, err := p.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -99,5 +205,5 @@ func (p *PolicyResource) GetById(id string) (*Policy, error) {
 		return policy, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
@@ -0,0 +1,103 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PollPolicy controls how often a task is polled until it's done,
+// and for how long.
+type PollPolicy struct {
+	// Wait time before the first poll, 0 for the default of 2 seconds
+	InitialInterval time.Duration
+
+	// Factor the wait time grows by after every poll, up to MaxInterval
+	// Values below 1 keep the wait time constant.
+	// MaxInterval is 0 for the default of 30 seconds.
+	Multiplier  float64
+	MaxInterval time.Duration
+
+	// Maximum total wait for the task, 0 to wait until the context is done
+	Timeout time.Duration
+}
+
+// DefaultPollPolicy returns the poll policy of new clients.
+// Tasks are polled after 2 seconds, then less and less often, without a timeout.
+func DefaultPollPolicy() *PollPolicy {
+	return &PollPolicy{
+		InitialInterval: 2 * time.Second,
+		Multiplier:      1.5,
+		MaxInterval:     30 * time.Second,
+	}
+}
+
+// interval returns the wait time before the given poll.
+// Zero intervals fall back to the ones of DefaultPollPolicy.
+func (p *PollPolicy) interval(poll int) time.Duration {
+	defaults := DefaultPollPolicy()
+
+	wait, maxWait := p.InitialInterval, p.MaxInterval
+	if wait <= 0 {
+		wait = defaults.InitialInterval
+	}
+	if maxWait <= 0 {
+		maxWait = defaults.MaxInterval
+	}
+
+	for i := 1; i < poll && p.Multiplier > 1 && wait < maxWait; i++ {
+		wait = time.Duration(float64(wait) * p.Multiplier)
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	return wait
+}
+
+// ErrTaskTimeout is matched by the errors of the tasks
+// which are not done within the timeout of the poll policy.
+var ErrTaskTimeout = errors.New("Task timed out")
+
+// TaskTimeoutError is returned when a task is not done
+// within the timeout of the poll policy.
+// It holds the last known state of the task.
+type TaskTimeoutError struct {
+	TaskId   string
+	State    string
+	Progress int
+	Timeout  time.Duration
+}
+
+func (e *TaskTimeoutError) Error() string {
+	return fmt.Sprintf("Task %s is still %s (%d%%) after %v", e.TaskId, e.State, e.Progress, e.Timeout)
+}
+
+// Is matches ErrTaskTimeout.
+func (e *TaskTimeoutError) Is(target error) bool {
+	return target == ErrTaskTimeout
+}
+
+// Key of the poll policy in a context
+type pollPolicyKey struct{}
+
+// ContextWithPollPolicy returns a copy of ctx which carries the poll policy.
+// The tasks waited for with the context use it instead of the policy of the client,
+// e.g. to set the timeout of a single Clone call.
+func ContextWithPollPolicy(ctx context.Context, policy *PollPolicy) context.Context {
+	return context.WithValue(ctx, pollPolicyKey{}, policy)
+}
+
+// pollPolicy returns the poll policy of the context, or else of the client.
+func (c *Client) pollPolicy(ctx context.Context) *PollPolicy {
+	if policy, ok := ctx.Value(pollPolicyKey{}).(*PollPolicy); ok && policy != nil {
+		return policy
+	}
+
+	if c != nil && c.PollPolicy != nil {
+		return c.PollPolicy
+	}
+
+	return DefaultPollPolicy()
+}
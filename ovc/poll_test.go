@@ -0,0 +1,102 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPollPolicyInterval(t *testing.T) {
+	policy := &PollPolicy{InitialInterval: time.Second, Multiplier: 2, MaxInterval: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, interval := range expected {
+		if got := policy.interval(i + 1); got != interval {
+			t.Errorf("interval(%d) = %v, expected %v", i+1, got, interval)
+		}
+	}
+
+	constant := &PollPolicy{InitialInterval: time.Second}
+	if got := constant.interval(10); got != time.Second {
+		t.Errorf("interval(10) = %v, expected a constant interval of 1s", got)
+	}
+
+	// A policy with only a timeout polls at the default intervals
+	timeoutOnly := &PollPolicy{Timeout: time.Minute}
+	if got := timeoutOnly.interval(1); got != 2*time.Second {
+		t.Errorf("interval(1) = %v, expected the default of 2s", got)
+	}
+
+	growing := &PollPolicy{InitialInterval: time.Second, Multiplier: 2}
+	if got := growing.interval(10); got != 30*time.Second {
+		t.Errorf("interval(10) = %v, expected the default maximum of 30s", got)
+	}
+}
+
+func TestWaitForTaskTimeout(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "IN_PROGRESS", "id": "1", "percent_complete": 42}}`)
+	})
+
+	client.PollPolicy = &PollPolicy{InitialInterval: 10 * time.Millisecond, Timeout: 100 * time.Millisecond}
+
+	resp := []byte(`{"task":{"state": "IN_PROGRESS", "id": "1"}}`)
+	_, err := client.Tasks.WaitForTask(resp)
+	if !errors.Is(err, ErrTaskTimeout) {
+		t.Fatalf("Returned error = %v, expected ErrTaskTimeout", err)
+	}
+
+	var timeoutErr *TaskTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Returned error = %v, expected a *TaskTimeoutError", err)
+	}
+	expected := TaskTimeoutError{TaskId: "1", State: "IN_PROGRESS", Progress: 42, Timeout: 100 * time.Millisecond}
+	if *timeoutErr != expected {
+		t.Errorf("Returned error = %+v, expected %+v", *timeoutErr, expected)
+	}
+}
+
+func TestContextWithPollPolicy(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "IN_PROGRESS", "id": "1"}}`)
+	})
+
+	// The policy of the call overrides the policy of the client.
+	client.PollPolicy = &PollPolicy{InitialInterval: time.Hour}
+	ctx := ContextWithPollPolicy(context.Background(), &PollPolicy{InitialInterval: time.Millisecond, Timeout: 50 * time.Millisecond})
+
+	resp := []byte(`{"task":{"state": "IN_PROGRESS", "id": "1"}}`)
+	_, err := client.Tasks.WaitForTaskWithContext(ctx, resp)
+	if !errors.Is(err, ErrTaskTimeout) {
+		t.Errorf("Returned error = %v, expected ErrTaskTimeout", err)
+	}
+}
+
+func TestWaitForTaskMalformedResponse(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"message": "no task"}`)
+	})
+
+	resp := []byte(`{"task":{"state": "IN_PROGRESS", "id": "1"}}`)
+	_, err := client.Tasks.WaitForTask(resp)
+	if !errors.Is(err, ErrNoTask) {
+		t.Errorf("Returned error = %v, expected ErrNoTask", err)
+	}
+
+	_, err = client.Tasks.WaitForTask([]byte(`not json`))
+	if err == nil {
+		t.Error("Malformed task response should return an error")
+	}
+}
@@ -0,0 +1,126 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests
+// after transient failures of the OVC.
+type RetryPolicy struct {
+	// Maximum number of attempts of a request, including the first one
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// Wait time before the first retry
+	// It's doubled after every attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Fraction of the wait time which is randomized, between 0 and 1
+	Jitter float64
+
+	// HTTP status codes of the OVC responses to retry
+	StatusCodes []int
+
+	// Retry errors of the connection to the OVC, e.g. refused or reset connections
+	NetworkErrors bool
+
+	// HTTP methods of the requests to retry
+	// Only add POST if the retried actions are safe to repeat.
+	Methods []string
+}
+
+// DefaultRetryPolicy returns the retry policy of new clients.
+// Only GET requests are retried, on gateway errors and network errors.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+		StatusCodes: []int{
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		NetworkErrors: true,
+		Methods:       []string{"GET"},
+	}
+}
+
+// retryable reports whether a request with the method which failed
+// with err should be retried.
+func (p *RetryPolicy) retryable(method string, err error) bool {
+	methodAllowed := false
+	for _, m := range p.Methods {
+		if m == method {
+			methodAllowed = true
+			break
+		}
+	}
+	if !methodAllowed {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range p.StatusCodes {
+			if code == apiErr.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The caller gave up on the request.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Any other error of a call to the OVC comes from the connection.
+	return p.NetworkErrors
+}
+
+// backoff returns the wait time after the given attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		wait += time.Duration(p.Jitter * float64(wait) * (2*rand.Float64() - 1))
+	}
+
+	return wait
+}
+
+// retry makes calls to the OVC using call until one succeeds,
+// fails with an error the retry policy of the client doesn't cover,
+// or runs out of attempts.
+func (c *Client) retry(ctx context.Context, method string, call func() ([]byte, error)) ([]byte, error) {
+	policy := c.RetryPolicy
+
+	for attempt := 1; ; attempt++ {
+		data, err := call()
+		if err == nil || policy == nil || attempt >= policy.MaxAttempts || !policy.retryable(method, err) {
+			return data, err
+		}
+
+		wait := policy.backoff(attempt)
+		c.log().Warn("Retrying OVC request", "method", method, "attempt", attempt, "wait", wait, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package ovc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+	return policy
+}
+
+func TestRetryGateway(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Number of attempts = %d, expected 3", attempts)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Returned error = %v, expected %v", err, ErrServerError)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Number of attempts = %d, expected 3", attempts)
+	}
+}
+
+func TestRetryPOST(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		testRequestBody(t, r, `{"name":"test"}`+"\n")
+		if attempts < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	body := map[string]string{"name": "test"}
+
+	// POST requests are not retried by default
+	_, err := client.DoRequest("POST", "/test/path", "", body, nil)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Returned error = %v, expected %v", err, ErrServerError)
+	}
+	if attempts != 1 {
+		t.Errorf("Number of attempts = %d, expected 1", attempts)
+	}
+
+	attempts = 0
+	client.RetryPolicy.Methods = append(client.RetryPolicy.Methods, "POST")
+	_, err = client.DoRequest("POST", "/test/path", "", body, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if attempts != 2 {
+		t.Errorf("Number of attempts = %d, expected 2", attempts)
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			// Drop the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if attempts != 2 {
+		t.Errorf("Number of attempts = %d, expected 2", attempts)
+	}
+}
+
+func TestRetryNotFound(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.DoRequest("GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Returned error = %v, expected %v", err, ErrNotFound)
+	}
+	if attempts != 1 {
+		t.Errorf("Number of attempts = %d, expected 1", attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wait := range expected {
+		if got := policy.backoff(i + 1); got != wait {
+			t.Errorf("Backoff after attempt %d = %v, expected %v", i+1, got, wait)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(1)
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Errorf("Backoff with jitter = %v, expected between 500ms and 1.5s", got)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package ovc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNoTaskJournal is returned by Tasks.Resume when the client has no task journal.
+var ErrNoTaskJournal = errors.New("Client has no task journal")
+
+// TaskJournal records the tasks started by a client in a local file,
+// so a restarted process can resume waiting for the tasks it started.
+// The file holds one JSON entry per line: an entry when a task is started,
+// and another one when it's seen done.
+// The file grows with every task, use Compact to drop the finished tasks.
+// Tasks.Resume compacts the journal of the client.
+// A journal is safe for concurrent use within a process.
+type TaskJournal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// JournalEntry is a task recorded in a task journal.
+type JournalEntry struct {
+	TaskId    string    `json:"task_id"`
+	Operation string    `json:"operation,omitempty"`
+	SourceId  string    `json:"source_id,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+
+	// Final state of the task, empty until it's done
+	State string `json:"state,omitempty"`
+}
+
+// NewTaskJournal creates a task journal stored in the file at path.
+// The file is created when the first task is recorded.
+func NewTaskJournal(path string) *TaskJournal {
+	return &TaskJournal{path: path}
+}
+
+// Entries returns the tasks recorded in the journal, in the order they were started.
+func (j *TaskJournal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.entries()
+}
+
+// entries reads the entries of the journal file.
+// Callers must hold the lock of the journal.
+func (j *TaskJournal) entries() ([]JournalEntry, error) {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		entries []JournalEntry
+		index   = map[string]int{}
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return nil, err
+		}
+
+		// Entries of finished tasks only update the state of the started tasks.
+		if i, ok := index[entry.TaskId]; ok {
+			if entry.State != "" {
+				entries[i].State = entry.State
+			}
+			continue
+		}
+
+		index[entry.TaskId] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Pending returns the tasks of the journal which were not seen done.
+func (j *TaskJournal) Pending() ([]JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []JournalEntry{}
+	for _, entry := range entries {
+		if entry.State == "" {
+			pending = append(pending, entry)
+		}
+	}
+
+	return pending, nil
+}
+
+// Compact rewrites the journal file with the tasks which were not seen done only.
+func (j *TaskJournal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.entries()
+	if err != nil || entries == nil {
+		return err
+	}
+
+	// Write a new file next to the journal, and replace the journal with it
+	// so a crash never leaves a partial journal.
+	tmp, err := ioutil.TempFile(filepath.Dir(j.path), filepath.Base(j.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		if entry.State != "" {
+			continue
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(data, '\n'))
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), j.path)
+}
+
+// add appends the entry to the journal file.
+func (j *TaskJournal) add(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// startTask decodes the task of the response to an operation on the object
+// with the source id, and records it in the task journal of the client.
+func (c *Client) startTask(resp []byte, operation string, sourceId string) (*Task, error) {
+	task, err := newTask(c, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Operation = operation
+	task.SourceId = sourceId
+
+	if c.TaskJournal != nil {
+		err = c.TaskJournal.add(JournalEntry{TaskId: task.Id, Operation: operation, SourceId: sourceId, StartedAt: time.Now()})
+		if err != nil {
+			// The operation is started anyway, so only the tracking is lost.
+			c.log().Error("Recording the task failed", "id", task.Id, "operation", operation, "error", err)
+		}
+	}
+
+	if task.Done() {
+		c.finishTask(task.Id, task.State)
+	}
+
+	return task, nil
+}
+
+// finishTask records the final state of the task in the task journal of the client.
+func (c *Client) finishTask(id string, state string) {
+	if c.TaskJournal == nil {
+		return
+	}
+
+	err := c.TaskJournal.add(JournalEntry{TaskId: id, State: state})
+	if err != nil {
+		c.log().Error("Recording the task state failed", "id", id, "error", err)
+	}
+}
+
+// Resume gets the tasks of the task journal of the client which were not seen done,
+// e.g. after a restart, so they can be waited for. Tasks which finished in the meantime
+// are returned too, to reconcile their results. Tasks unknown to the OVC are recorded
+// with the UNKNOWN state and skipped.
+// The finished tasks are then compacted out of the journal.
+func (s *TaskResource) Resume() ([]*Task, error) {
+	return s.ResumeWithContext(context.Background())
+}
+
+// ResumeWithContext is like Resume but uses ctx for the requests to the OVC.
+func (s *TaskResource) ResumeWithContext(ctx context.Context) ([]*Task, error) {
+	journal := s.client.TaskJournal
+	if journal == nil {
+		return nil, ErrNoTaskJournal
+	}
+
+	pending, err := journal.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*Task{}
+	for _, entry := range pending {
+		task, err := s.GetByIdWithContext(ctx, entry.TaskId)
+		if errors.Is(err, ErrNotFound) {
+			s.client.finishTask(entry.TaskId, "UNKNOWN")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		task.Operation = entry.Operation
+		task.SourceId = entry.SourceId
+		if task.Done() {
+			s.client.finishTask(task.Id, task.State)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	err = journal.Compact()
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,170 @@
+package ovc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestJournal(t *testing.T) (*TaskJournal, func()) {
+	dir, err := ioutil.TempDir("", "ovc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewTaskJournal(filepath.Join(dir, "tasks.json")), func() { os.RemoveAll(dir) }
+}
+
+func TestTasksGetById(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTaskRequest(apiHandler)
+
+	task, err := client.Tasks.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Id != "1" || task.State != "COMPLETE" || len(task.AffectedResources) != 1 {
+		t.Errorf("Returned task %+v, expected the completed task 1", task)
+	}
+}
+
+func TestTaskJournal(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	journal, remove := newTestJournal(t)
+	defer remove()
+	client.TaskJournal = journal
+
+	mockGetVMById(apiHandler)
+	mockTaskRequest(apiHandler)
+	apiHandler.HandleFunc("/virtual_machines/1/clone", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1"}}`)
+	})
+
+	vm, err := client.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := vm.CloneAsync("testvm", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := journal.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].TaskId != "1" || pending[0].Operation != "VirtualMachine.Clone" || pending[0].SourceId != "1" {
+		t.Fatalf("Pending tasks = %+v, expected the clone task", pending)
+	}
+
+	err = task.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].State != "COMPLETE" {
+		t.Errorf("Journal entries = %+v, expected the completed clone task", entries)
+	}
+}
+
+func TestTasksResume(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	journal, remove := newTestJournal(t)
+	defer remove()
+	client.TaskJournal = journal
+
+	// Tasks started before a restart
+	journal.add(JournalEntry{TaskId: "1", Operation: "VirtualMachine.Clone", SourceId: "10"})
+	journal.add(JournalEntry{TaskId: "2", Operation: "VirtualMachine.Move", SourceId: "20"})
+	journal.add(JournalEntry{TaskId: "3", Operation: "Backup.Delete", SourceId: "30"})
+	journal.add(JournalEntry{TaskId: "3", State: "COMPLETED"})
+
+	mockTaskRequest(apiHandler)
+	apiHandler.HandleFunc("/tasks/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Task not found"}`)
+	})
+
+	tasks, err := client.Tasks.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tasks) != 1 || tasks[0].Id != "1" || tasks[0].Operation != "VirtualMachine.Clone" || tasks[0].SourceId != "10" {
+		t.Fatalf("Resumed tasks = %+v, expected the clone task", tasks)
+	}
+
+	pending, err := journal.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Pending tasks = %+v, expected none", pending)
+	}
+
+	// The finished tasks are compacted out of the journal
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Journal entries = %+v, expected none", entries)
+	}
+}
+
+func TestTaskJournalCompact(t *testing.T) {
+	journal, remove := newTestJournal(t)
+	defer remove()
+
+	journal.add(JournalEntry{TaskId: "1", Operation: "VirtualMachine.Clone", SourceId: "10"})
+	journal.add(JournalEntry{TaskId: "2", Operation: "VirtualMachine.Move", SourceId: "20"})
+	journal.add(JournalEntry{TaskId: "1", State: "COMPLETED"})
+
+	err := journal.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].TaskId != "2" || entries[0].Operation != "VirtualMachine.Move" {
+		t.Errorf("Journal entries = %+v, expected the move task only", entries)
+	}
+
+	// Tasks recorded after a compaction are appended
+	journal.add(JournalEntry{TaskId: "3", Operation: "Backup.Delete", SourceId: "30"})
+	pending, err := journal.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("Pending tasks = %+v, expected the move and delete tasks", pending)
+	}
+}
+
+func TestTasksResumeWithoutJournal(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	_, err := client.Tasks.Resume()
+	if err != ErrNoTaskJournal {
+		t.Errorf("Returned error = %v, expected ErrNoTaskJournal", err)
+	}
+}
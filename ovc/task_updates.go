@@ -0,0 +1,105 @@
+package ovc
+
+import (
+	"context"
+)
+
+// TaskUpdate is the state of a task after a poll.
+type TaskUpdate struct {
+	Id                string
+	State             string
+	Progress          int
+	AffectedResources []*AffectedResource
+
+	// Set by the last update of the task
+	Done bool
+}
+
+// update returns the current state of the task.
+// Callers must hold the lock of the task.
+func (t *Task) update() TaskUpdate {
+	return TaskUpdate{
+		Id:                t.Id,
+		State:             t.State,
+		Progress:          t.Progress,
+		AffectedResources: t.AffectedResources,
+		Done:              t.State != "IN_PROGRESS",
+	}
+}
+
+// OnUpdate calls fn with the state of the task after every poll,
+// until the task is done. Updates are only sent while the task is polled,
+// e.g. by Wait.
+func (t *Task) OnUpdate(fn func(update TaskUpdate)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.callbacks = append(t.callbacks, fn)
+}
+
+// Updates returns a channel which receives the current state of the task,
+// then its state after every poll. The channel is closed once the task is done.
+// Updates are only sent while the task is polled, e.g. by Wait.
+// Polling never blocks on the channel: a slow reader misses the intermediate
+// updates, but always receives the last one.
+func (t *Task) Updates() <-chan TaskUpdate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan TaskUpdate, 1)
+	update := t.update()
+	ch <- update
+	if update.Done {
+		close(ch)
+	} else {
+		t.updates = append(t.updates, ch)
+	}
+
+	return ch
+}
+
+// publish sends the update to the channels of the task, and closes them
+// once the task is done. Returns the callbacks to call with the update.
+// Callers must hold the lock of the task.
+func (t *Task) publish(update TaskUpdate) []func(TaskUpdate) {
+	for _, ch := range t.updates {
+		// Replace the update the reader did not get yet.
+		select {
+		case ch <- update:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- update
+		}
+
+		if update.Done {
+			close(ch)
+		}
+	}
+
+	callbacks := t.callbacks
+	if update.Done {
+		t.updates = nil
+		t.callbacks = nil
+	}
+
+	return callbacks
+}
+
+// Key of the task update callback in a context
+type taskUpdateKey struct{}
+
+// ContextWithTaskUpdates returns a copy of ctx which carries fn.
+// The tasks polled with the context call fn with their state after every poll,
+// e.g. to show the progress of a Move.
+func ContextWithTaskUpdates(ctx context.Context, fn func(update TaskUpdate)) context.Context {
+	return context.WithValue(ctx, taskUpdateKey{}, fn)
+}
+
+// taskUpdateCallback returns the task update callback of the context, if any.
+func taskUpdateCallback(ctx context.Context) func(TaskUpdate) {
+	fn, _ := ctx.Value(taskUpdateKey{}).(func(TaskUpdate))
+	return fn
+}
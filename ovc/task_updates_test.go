@@ -0,0 +1,113 @@
+package ovc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// mockTaskProgress serves a task which progresses by 50% on every poll.
+func mockTaskProgress(apiHandler *http.ServeMux) {
+	var mu sync.Mutex
+	progress := 0
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress += 50
+		if progress < 100 {
+			fmt.Fprintf(w, `{"task": {"state": "IN_PROGRESS", "id": "1", "percent_complete": %d}}`, progress)
+		} else {
+			fmt.Fprint(w, `{"task": {"state": "COMPLETED", "id": "1", "percent_complete": 100, "affected_objects": [{"object_id": "2"}]}}`)
+		}
+	})
+}
+
+func TestTaskOnUpdate(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTaskProgress(apiHandler)
+
+	task, err := newTask(client, []byte(`{"task": {"state": "IN_PROGRESS", "id": "1"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []int
+	task.OnUpdate(func(update TaskUpdate) {
+		progress = append(progress, update.Progress)
+		if update.Done && update.AffectedResources[0].ObjectId != "2" {
+			t.Errorf("Last update has the affected objects %v, expected 2", update.AffectedResources)
+		}
+	})
+
+	err = task.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []int{50, 100}; !reflect.DeepEqual(progress, expected) {
+		t.Errorf("Updates with progress %v, expected %v", progress, expected)
+	}
+}
+
+func TestTaskUpdates(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTaskProgress(apiHandler)
+
+	task, err := newTask(client, []byte(`{"task": {"state": "IN_PROGRESS", "id": "1"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := task.Updates()
+	done := make(chan error)
+	go func() {
+		done <- task.Wait(context.Background())
+	}()
+
+	var last TaskUpdate
+	for update := range updates {
+		last = update
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if !last.Done || last.State != "COMPLETED" || last.Progress != 100 {
+		t.Errorf("Last update = %+v, expected the completed task", last)
+	}
+
+	// The channel of a finished task only receives its final state.
+	final, ok := <-task.Updates()
+	if !ok || !final.Done {
+		t.Errorf("Update of the finished task = %+v, expected its final state", final)
+	}
+}
+
+func TestContextWithTaskUpdates(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTaskProgress(apiHandler)
+
+	var states []string
+	ctx := ContextWithTaskUpdates(context.Background(), func(update TaskUpdate) {
+		states = append(states, update.State)
+	})
+
+	_, err := client.Tasks.WaitForTaskWithContext(ctx, []byte(`{"task": {"state": "IN_PROGRESS", "id": "1"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"IN_PROGRESS", "COMPLETED"}; !reflect.DeepEqual(states, expected) {
+		t.Errorf("Updates with states %v, expected %v", states, expected)
+	}
+}
@@ -0,0 +1,90 @@
+package ovc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WaitAllOptions controls how WaitAll waits for the tasks.
+type WaitAllOptions struct {
+	// Maximum number of polls per second, shared by all the tasks
+	// 0 polls the tasks as often as their poll policy allows.
+	PollsPerSecond float64
+
+	// Stop waiting for the other tasks as soon as one fails
+	// The tasks still running are reported with a context.Canceled error.
+	FailFast bool
+}
+
+// TaskResult is the outcome of a task waited for by WaitAll.
+type TaskResult struct {
+	Task *Task
+
+	// Error of the wait for the task, e.g. a *TaskError if it failed
+	Err error
+}
+
+// WaitAll waits for the tasks concurrently, following the options,
+// until they're all done or ctx is done.
+// Returns the result of each task by task id, and a *MultiError
+// with the errors of the failed waits by task id.
+func (s *TaskResource) WaitAll(ctx context.Context, tasks []*Task, opts *WaitAllOptions) (map[string]*TaskResult, error) {
+	if opts == nil {
+		opts = &WaitAllOptions{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var limit func(ctx context.Context) error
+	if opts.PollsPerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.PollsPerSecond))
+		defer ticker.Stop()
+
+		limit = func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				return nil
+			}
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]*TaskResult, len(tasks))
+	)
+
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(task *Task) {
+			defer wg.Done()
+
+			err := task.wait(ctx, limit)
+			if err != nil && opts.FailFast {
+				cancel()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[task.Id] = &TaskResult{Task: task, Err: err}
+		}(task)
+	}
+	wg.Wait()
+
+	multiErr := &MultiError{Errors: map[string]error{}}
+	for id, result := range results {
+		if result.Err != nil {
+			multiErr.Errors[id] = result.Err
+		}
+	}
+
+	if len(multiErr.Errors) > 0 {
+		return results, multiErr
+	}
+
+	return results, nil
+}
@@ -0,0 +1,132 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockTasks serves the tasks with the given final states after a few polls.
+// Tasks with the IN_PROGRESS final state never finish.
+func mockTasks(apiHandler *http.ServeMux, states map[string]string) {
+	var mu sync.Mutex
+	polls := map[string]int{}
+	for id, state := range states {
+		id, state := id, state
+		apiHandler.HandleFunc("/tasks/"+id, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			polls[id]++
+			current := state
+			if polls[id] < 3 {
+				current = "IN_PROGRESS"
+			}
+			mu.Unlock()
+
+			fmt.Fprintf(w, `{"task": {"state": "%s", "id": "%s"}}`, current, id)
+		})
+	}
+}
+
+func newTestTasks(t *testing.T, client *Client, ids ...string) []*Task {
+	tasks := []*Task{}
+	for _, id := range ids {
+		task, err := newTask(client, []byte(fmt.Sprintf(`{"task": {"state": "IN_PROGRESS", "id": "%s"}}`, id)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
+
+func TestWaitAll(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTasks(apiHandler, map[string]string{"1": "COMPLETED", "2": "FAILED", "3": "COMPLETED"})
+	tasks := newTestTasks(t, client, "1", "2", "3")
+
+	results, err := client.Tasks.WaitAll(context.Background(), tasks, nil)
+	if !errors.Is(err, ErrTaskFailed) {
+		t.Errorf("Returned error = %v, expected to match ErrTaskFailed", err)
+	}
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 1 || multiErr.Errors["2"] == nil {
+		t.Errorf("Returned error = %v, expected the error of task 2", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Returned %d results, expected 3", len(results))
+	}
+	for id, state := range map[string]string{"1": "COMPLETED", "2": "FAILED", "3": "COMPLETED"} {
+		if results[id].Task.State != state {
+			t.Errorf("Task %s state = %s, expected %s", id, results[id].Task.State, state)
+		}
+	}
+	if results["1"].Err != nil || results["2"].Err == nil {
+		t.Errorf("Task errors = %v and %v, expected only task 2 to fail", results["1"].Err, results["2"].Err)
+	}
+}
+
+func TestWaitAllFailFast(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTasks(apiHandler, map[string]string{"1": "IN_PROGRESS", "2": "FAILED"})
+	tasks := newTestTasks(t, client, "1", "2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := client.Tasks.WaitAll(ctx, tasks, &WaitAllOptions{FailFast: true})
+	if !errors.Is(err, ErrTaskFailed) {
+		t.Errorf("Returned error = %v, expected to match ErrTaskFailed", err)
+	}
+	if !errors.Is(results["1"].Err, context.Canceled) {
+		t.Errorf("Task 1 error = %v, expected context.Canceled", results["1"].Err)
+	}
+}
+
+func TestWaitAllDeadline(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTasks(apiHandler, map[string]string{"1": "IN_PROGRESS", "2": "COMPLETED"})
+	tasks := newTestTasks(t, client, "1", "2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results, err := client.Tasks.WaitAll(ctx, tasks, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Returned error = %v, expected context.DeadlineExceeded", err)
+	}
+	if results["2"].Err != nil {
+		t.Errorf("Task 2 error = %v, expected it to complete", results["2"].Err)
+	}
+}
+
+func TestWaitAllRateLimit(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockTasks(apiHandler, map[string]string{"1": "COMPLETED", "2": "COMPLETED"})
+	tasks := newTestTasks(t, client, "1", "2")
+
+	// 6 polls at 20 polls per second
+	start := time.Now()
+	_, err := client.Tasks.WaitAll(context.Background(), tasks, &WaitAllOptions{PollsPerSecond: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("Tasks were polled in %v, expected at least 250ms", elapsed)
+	}
+}
@@ -1,9 +1,11 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
 	"time"
 )
 
@@ -13,12 +15,26 @@ import (
 type TaskResource resourceClient
 
 // Task fields
+// Tasks returned by the Async methods are handles bound to the OVC client
+// which started them. Use Wait, Poll and Done to follow them.
 type Task struct {
 	State             string              `json:"state,omitempty"`
 	Id                string              `json:"id,omitempty"`
 	Progress          int                 `json:"percent_complete,omitempty"`
 	AffectedResources []*AffectedResource `json:"affected_objects,omitempty"`
 	ErrorCode         int                 `json:"error_code,omitempty"`
+	Messages          TaskMessages        `json:"messages,omitempty"`
+
+	// Operation which started the task, e.g. VirtualMachine.Clone,
+	// and the id of the object it was started on
+	// Only set for the tasks started or resumed by the client.
+	Operation string `json:"-"`
+	SourceId  string `json:"-"`
+
+	client    *Client            // OVC client which started the task
+	mu        sync.Mutex         // Guards the fields updated by Poll and the subscribers
+	callbacks []func(TaskUpdate) // Subscribed with OnUpdate
+	updates   []chan TaskUpdate  // Subscribed with Updates
 }
 
 // List of affected resources in task response
@@ -27,53 +43,278 @@ type AffectedResource struct {
 	ObjectId   string `json:"object_id,omitempty"`
 }
 
+// TaskMessages are the messages of a task, e.g. the reasons it failed.
+type TaskMessages []string
+
+// UnmarshalJSON decodes the messages of a task,
+// given as a string or a list of strings or of objects with a message field.
+// Null and empty messages are left out.
+func (m *TaskMessages) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var message string
+	if json.Unmarshal(data, &message) == nil {
+		*m = TaskMessages{}
+		if message != "" {
+			*m = append(*m, message)
+		}
+		return nil
+	}
+
+	var items []json.RawMessage
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return err
+	}
+
+	*m = TaskMessages{}
+	for _, item := range items {
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(item, &message) == nil {
+			if message != "" {
+				*m = append(*m, message)
+			}
+		} else if json.Unmarshal(item, &obj) == nil && obj.Message != "" {
+			*m = append(*m, obj.Message)
+		}
+	}
+
+	return nil
+}
+
 // Task endpoint response
 type TaskResp struct {
 	Task *Task `json:"task,omitempty"`
 }
 
-// WaitForTask waits for the task to complete
-// Makes continous calls to the server using CheckProgress method 
-// and checks the status of the task
-func (s *TaskResource) WaitForTask(resp []byte) (*Task, error) {
-	var (
-		taskResp TaskResp
-		task     *Task
-		err      error
-	)
+// ErrNoTask is returned when a task endpoint response has no task.
+var ErrNoTask = errors.New("Response has no task")
 
-	for {
-		err = json.Unmarshal(resp, &taskResp)
-		if err != nil {
-			break
+// newTask decodes the task of a task endpoint response
+// and binds it to the client.
+func newTask(client *Client, resp []byte) (*Task, error) {
+	var taskResp TaskResp
+
+	err := json.Unmarshal(resp, &taskResp)
+	if err != nil {
+		return nil, err
+	}
+
+	if taskResp.Task == nil || taskResp.Task.Id == "" {
+		return nil, ErrNoTask
+	}
+
+	taskResp.Task.client = client
+	return taskResp.Task, nil
+}
+
+// Done reports whether the task has finished, successfully or not.
+func (t *Task) Done() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.State != "IN_PROGRESS"
+}
+
+// AffectedObjects returns the objects created or changed by the task,
+// e.g. the clone of a VM. They are only final once the task is done.
+func (t *Task) AffectedObjects() []*AffectedResource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.AffectedResources
+}
+
+// affects reports whether the task reports the object with the id as affected.
+func (t *Task) affects(id string) bool {
+	for _, object := range t.AffectedObjects() {
+		if object.ObjectId == id {
+			return true
 		}
+	}
 
-		log.Println(taskResp.Task.State)
-		if taskResp.Task.State != "IN_PROGRESS" {
-			task = taskResp.Task
-			break
+	return false
+}
+
+// Err returns a *TaskError if the task failed, nil otherwise.
+func (t *Task) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.State != "FAILED" {
+		return nil
+	}
+
+	return newTaskError(t)
+}
+
+// Poll updates the task with its current state in the OVC.
+func (t *Task) Poll() error {
+	return t.PollWithContext(context.Background())
+}
+
+// PollWithContext is like Poll but uses ctx for the requests to the OVC.
+func (t *Task) PollWithContext(ctx context.Context) error {
+	if t.client == nil {
+		return ErrNoClient
+	}
+
+	resp, err := t.client.Tasks.CheckProgressWithContext(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	polled, err := newTask(t.client, resp)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.State = polled.State
+	t.Progress = polled.Progress
+	t.AffectedResources = polled.AffectedResources
+	t.ErrorCode = polled.ErrorCode
+	t.Messages = polled.Messages
+
+	t.client.log().Debug("Task state", "id", t.Id, "state", t.State, "percent_complete", t.Progress)
+
+	update := t.update()
+	callbacks := t.publish(update)
+	t.mu.Unlock()
+
+	if update.Done {
+		t.client.finishTask(update.Id, update.State)
+	}
+
+	// Callbacks run without the lock, so they can use the task.
+	for _, callback := range callbacks {
+		callback(update)
+	}
+	if callback := taskUpdateCallback(ctx); callback != nil {
+		callback(update)
+	}
+
+	return nil
+}
+
+// Wait polls the task until it's done, following the poll policy
+// of the context or else of the client, see ContextWithPollPolicy.
+// Returns a *TaskError if the task failed, a *TaskTimeoutError if the task
+// is not done within the timeout of the policy, or the context error when ctx is done.
+func (t *Task) Wait(ctx context.Context) error {
+	return t.wait(ctx, nil)
+}
+
+// wait polls the task like Wait. If set, limit is called before every poll
+// and stops the wait when it returns an error.
+func (t *Task) wait(ctx context.Context, limit func(ctx context.Context) error) error {
+	policy := t.client.pollPolicy(ctx)
+
+	waitCtx := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	for poll := 1; !t.Done(); poll++ {
+		// Wait between the polls to avoid hitting the server continously
+		// Stop waiting as soon as the context is done
+		select {
+		case <-waitCtx.Done():
+			return t.waitError(ctx, policy)
+		case <-time.After(policy.interval(poll)):
 		}
-                 
-		// Sleep for two seconds if the request is in progress
-		// To avoid hitting the server continously
-		time.Sleep(2 * time.Second)
 
-		resp, err = s.CheckProgress(taskResp.Task)
+		var err error
+		if limit != nil {
+			err = limit(waitCtx)
+		}
+		if err == nil {
+			err = t.PollWithContext(waitCtx)
+		}
 		if err != nil {
-			break
+			if waitCtx.Err() != nil {
+				return t.waitError(ctx, policy)
+			}
+			return err
 		}
 	}
 
-	return task, err
+	return t.Err()
+}
+
+// waitError returns the error of a wait stopped by the context
+// of the caller or by the timeout of the poll policy.
+func (t *Task) waitError(ctx context.Context, policy *PollPolicy) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &TaskTimeoutError{TaskId: t.Id, State: t.State, Progress: t.Progress, Timeout: policy.Timeout}
+}
+
+// WaitForTask waits for the task to complete
+// Polls the server using CheckProgress method following the poll policy of the client
+// and checks the status of the task
+func (s *TaskResource) WaitForTask(resp []byte) (*Task, error) {
+	return s.WaitForTaskWithContext(context.Background(), resp)
+}
+
+// WaitForTaskWithContext is like WaitForTask but uses ctx for the requests to the OVC.
+// Polling stops and the context error is returned when ctx is done.
+func (s *TaskResource) WaitForTaskWithContext(ctx context.Context, resp []byte) (*Task, error) {
+	task, err := newTask(s.client, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// GetById gets a task by its id, e.g. to follow a task started by another process.
+func (s *TaskResource) GetById(id string) (*Task, error) {
+	return s.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (s *TaskResource) GetByIdWithContext(ctx context.Context, id string) (*Task, error) {
+	var (
+		path = fmt.Sprintf("/tasks/%s", id)
+	)
+
+	resp, err := s.client.DoRequestWithContext(ctx, "GET", path, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTask(s.client, resp)
 }
 
 // CheckProgress makes call to the server for task status.
 func (s *TaskResource) CheckProgress(task *Task) ([]byte, error) {
+	return s.CheckProgressWithContext(context.Background(), task)
+}
+
+// CheckProgressWithContext is like CheckProgress but uses ctx for the requests to the OVC.
+func (s *TaskResource) CheckProgressWithContext(ctx context.Context, task *Task) ([]byte, error) {
 	var (
 		path = fmt.Sprintf("/tasks/%s", task.Id)
 	)
 
-	resp, err := s.client.DoRequest("GET", path, "", nil, nil)
+	resp, err := s.client.DoRequestWithContext(ctx, "GET", path, "", nil, nil)
 
 	return resp, err
 }
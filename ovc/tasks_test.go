@@ -0,0 +1,81 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitForTask(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	resp := []byte(`{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	task, err := client.Tasks.WaitForTask(resp)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if task.State != "COMPLETE" {
+		t.Errorf("Task state = %v, expected COMPLETE", task.State)
+	}
+}
+
+func TestWaitForTaskWithContextCancelled(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	apiHandler.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"task": {"state": "IN_PROGRESS", "id": "1"}}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	resp := []byte(`{"task":{"state": "IN_PROGRESS", "id": "1"}}`)
+	start := time.Now()
+	_, err := client.Tasks.WaitForTaskWithContext(ctx, resp)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Returned error = %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WaitForTaskWithContext returned after %v, expected to stop on context deadline", elapsed)
+	}
+}
+
+func TestWaitForTaskWithoutTask(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	for _, resp := range []string{`{}`, `{"task": {}}`} {
+		_, err := client.Tasks.WaitForTask([]byte(resp))
+		if !errors.Is(err, ErrNoTask) {
+			t.Errorf("Returned error = %v for %s, expected ErrNoTask", err, resp)
+		}
+	}
+}
+
+func TestTaskWaitDone(t *testing.T) {
+	task := &Task{Id: "1", State: "COMPLETED"}
+
+	// A finished task is not polled again.
+	err := task.Wait(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnboundTaskPoll(t *testing.T) {
+	task := &Task{Id: "1", State: "IN_PROGRESS"}
+
+	if err := task.Poll(); err != ErrNoClient {
+		t.Errorf("Returned error = %v, expected ErrNoClient", err)
+	}
+}
@@ -0,0 +1,276 @@
+package ovc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// The access token is refreshed this long before it expires.
+const tokenExpiryDelta = time.Minute
+
+// Token is an access token of the OVC API.
+type Token struct {
+	AccessToken string `json:"access_token,omitempty"`
+
+	// Expiry time of the token
+	// Zero if the expiry is unknown.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// valid reports whether the token can still be used for the next request.
+func (t Token) valid() bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry))
+}
+
+// TokenSource supplies the access tokens of an OVC client.
+//
+// Token is called when the client has no token yet, when the current token
+// is about to expire and when the OVC rejects the current token.
+// The client passed to Token can be used to make calls to the OVC.
+type TokenSource interface {
+	Token(ctx context.Context, c *Client) (*Token, error)
+}
+
+// PasswordTokenSource gets tokens from the login endpoint
+// using the password grant.
+type PasswordTokenSource struct {
+	Username string
+	Password string
+}
+
+// Auth token endpoint response.
+type auth struct {
+	AccessToken string `json:"access_token,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+}
+
+// Token makes a call to the login endpoint and returns a new token.
+func (p *PasswordTokenSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	endpoint, err := c.CreateResourceURL("/oauth/token", "")
+	if err != nil {
+		return nil, err
+	}
+
+	//Sets form data of the login API
+	reqData := url.Values{}
+	reqData.Set("username", p.Username)
+	reqData.Set("password", p.Password)
+	reqData.Set("grant_type", "password")
+
+	//Creates new http request with URL encoded body
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.String(), bytes.NewBufferString(reqData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	//Login API expects URL encoded body
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+	//Basic auth should be set with username simplivity and empty password
+	req.SetBasicAuth("simplivity", "")
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	//Makes request to the login API
+	data, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	//Unmarshal the login API response to get the token
+	authData := auth{}
+	err = json.Unmarshal(data, &authData)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{AccessToken: authData.AccessToken}
+	if authData.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(authData.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// StaticTokenSource always returns the same pre-issued token.
+// Requests fail once the OVC rejects the token.
+type StaticTokenSource struct {
+	AccessToken string
+}
+
+// Token returns the pre-issued token.
+func (s *StaticTokenSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	return &Token{AccessToken: s.AccessToken}, nil
+}
+
+// FileTokenSource caches the tokens of another token source in a file,
+// so that short lived processes can reuse a token instead of logging in
+// on every run.
+//
+// The first call to Token returns the cached token if it was issued by the
+// same OVC and hasn't expired. Every other call means the client needs a new
+// token, which is taken from Source and written to the file.
+type FileTokenSource struct {
+	// Path of the token cache file
+	Path string
+
+	// Source of the new tokens
+	Source TokenSource
+
+	mu     sync.Mutex
+	loaded bool
+}
+
+// NewFileTokenSource creates a token source which caches the tokens of src
+// in the file at path.
+func NewFileTokenSource(path string, src TokenSource) *FileTokenSource {
+	return &FileTokenSource{Path: path, Source: src}
+}
+
+// Content of the token cache file.
+type tokenFile struct {
+	OVCIP string `json:"ovc_ip,omitempty"`
+	Token
+}
+
+// Token returns the cached token or a new token from the source.
+func (f *FileTokenSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.loaded {
+		f.loaded = true
+		cached, err := f.read()
+		if err == nil && cached.OVCIP == c.OVCIP && cached.valid() {
+			return &cached.Token, nil
+		}
+	}
+
+	token, err := f.Source.Token(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.write(tokenFile{OVCIP: c.OVCIP, Token: *token})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// read reads the token cache file.
+func (f *FileTokenSource) read() (*tokenFile, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &tokenFile{}
+	err = json.Unmarshal(data, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+// write replaces the token cache file.
+// The file is only readable by the current user.
+func (f *FileTokenSource) write(cached tokenFile) error {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// tokenManager holds the access token of an OVC client.
+// It is safe for concurrent use and makes sure that only one login
+// happens when many requests find an expired token at the same time.
+type tokenManager struct {
+	mu    sync.RWMutex
+	token Token
+
+	// Serializes the logins
+	refreshMu sync.Mutex
+}
+
+// get returns the current token.
+func (m *tokenManager) get() Token {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.token
+}
+
+// GetAccessToken returns the access token currently used by the client.
+// It is safe to call while other requests are refreshing the token.
+func (c *Client) GetAccessToken() string {
+	return c.tokens.get().AccessToken
+}
+
+// setToken stores a new token.
+func (c *Client) setToken(token *Token) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+
+	c.tokens.token = *token
+	c.AccessToken = token.AccessToken
+}
+
+// accessToken returns a token which is valid for the next request.
+// Gets a new token first if there is no token yet or the token is about to expire.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	token := c.tokens.get()
+	if token.valid() {
+		return token.AccessToken, nil
+	}
+
+	return c.refreshToken(ctx, token.AccessToken)
+}
+
+// refreshToken replaces the stale token with a new one.
+// Concurrent callers with the same stale token wait for a single login
+// and share its result.
+func (c *Client) refreshToken(ctx context.Context, stale string) (string, error) {
+	c.tokens.refreshMu.Lock()
+	defer c.tokens.refreshMu.Unlock()
+
+	// Another request already got a new token
+	if token := c.GetAccessToken(); token != stale {
+		return token, nil
+	}
+
+	err := c.login(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.GetAccessToken(), nil
+}
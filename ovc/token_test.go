@@ -0,0 +1,192 @@
+package ovc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTokenRefreshedOnceForConcurrentRequests(t *testing.T) {
+	var logins int32
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&logins, 1)
+		fmt.Fprintf(w, `{"access_token": "token-%d"}`, n)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		// Only the token of the second login is accepted
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "invalid_token", "message": "Access token expired"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	client, err := NewClient("user", "pass", server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.DoRequest("GET", "/test/path", "", nil, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&logins); got != 2 {
+		t.Errorf("Number of logins = %d, expected 2", got)
+	}
+	if got := client.GetAccessToken(); got != "token-2" {
+		t.Errorf("Access token = %s, expected token-2", got)
+	}
+}
+
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	var logins int32
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&logins, 1)
+		// The token expires before tokenExpiryDelta is over
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 1}`, n)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "Authorization", "Bearer token-2")
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	client, err := NewClient("user", "pass", server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DoRequest("GET", "/test/path", "", nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if got := atomic.LoadInt32(&logins); got != 2 {
+		t.Errorf("Number of logins = %d, expected 2", got)
+	}
+}
+
+func TestTokenRefreshError(t *testing.T) {
+	var logins int32
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&logins, 1) > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "unauthorized", "message": "Bad credentials"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "12345"}`)
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "invalid_token", "message": "Access token expired"}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	client, err := NewClient("user", "pass", server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DoRequest("GET", "/test/path", "", nil, nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Returned error = %v, expected the login error", err)
+	}
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Static token source should not log in")
+	})
+	apiHandler.HandleFunc("/test/path", func(w http.ResponseWriter, r *http.Request) {
+		testRequestHeader(t, r, "Authorization", "Bearer static")
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	client, err := NewClientWithTokenSource(&StaticTokenSource{AccessToken: "static"}, server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DoRequest("GET", "/test/path", "", nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFileTokenSource(t *testing.T) {
+	var logins int32
+	apiHandler := http.NewServeMux()
+	apiHandler.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, formValues{"username": "user", "password": "pass", "grant_type": "password"})
+		n := atomic.AddInt32(&logins, 1)
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, n)
+	})
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ovc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token.json")
+
+	// Each client simulates a new run of a short lived process.
+	for i := 0; i < 3; i++ {
+		src := NewFileTokenSource(path, &PasswordTokenSource{Username: "user", Password: "pass"})
+		client, err := NewClientWithTokenSource(src, server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := client.GetAccessToken(); got != "token-1" {
+			t.Errorf("Access token = %s, expected token-1", got)
+		}
+	}
+
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Errorf("Number of logins = %d, expected 1", got)
+	}
+
+	// A token of another OVC is not reused.
+	err = ioutil.WriteFile(path, []byte(`{"ovc_ip": "other", "access_token": "foreign"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewFileTokenSource(path, &PasswordTokenSource{Username: "user", Password: "pass"})
+	client, err := NewClientWithTokenSource(src, server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := client.GetAccessToken(); got != "token-2" {
+		t.Errorf("Access token = %s, expected token-2", got)
+	}
+}
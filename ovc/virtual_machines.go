@@ -1,10 +1,12 @@
 package ovc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"sync"
+	"time"
 )
 
 // VirtualMachineResource handles communications with the the VM resource methods
@@ -48,9 +50,79 @@ type VirtualMachine struct {
 	ComputeClusterName                     string           `json:"cumpute_cluster_name,omitempty"`
 	ClusterGroupIds                        []string         `json:"cluster_group_ids,omitempty"`
 	ReplicaSet                             []ReplicaSetList `json:"replica_set,omitempty"`
+	PowerState                             PowerState       `json:"hypervisor_virtual_machine_power_state,omitempty"`
+
+	// OVC client used for the VM operations
+	client *Client
+}
+
+// VMState is the state of a virtual machine.
+type VMState string
+
+// Virtual machine states
+const (
+	VMStateAlive   VMState = "ALIVE"
+	VMStateDeleted VMState = "DELETED"
+	VMStateRemoved VMState = "REMOVED"
+)
+
+// VirtualMachineFilter is a typed filter of the virtual machines.
+// See GetAll for the meaning of the fields.
+type VirtualMachineFilter struct {
+	Ids                                     []string
+	Names                                   []string
+	OmniStackClusterIds                     []string
+	OmniStackClusterNames                   []string
+	ComputeClusterParentHypervisorObjectIds []string
+	ComputeClusterParentNames               []string
+	HypervisorManagementSystems             []string
+	HypervisorManagementSystemNames         []string
+	DatastoreIds                            []string
+	DatastoreNames                          []string
+	PolicyIds                               []string
+	PolicyNames                             []string
+	HypervisorObjectIds                     []string
+	CreatedAfter                            time.Time
+	CreatedBefore                           time.Time
+	States                                  []VMState
+	AppAwareVMStatuses                      []string
+	HypervisorIsTemplate                    *bool
+	HostId                                  string
+}
+
+// Filters returns the filters to set in GetAllParams.
+func (f VirtualMachineFilter) Filters() map[string]string {
+	states := make([]string, len(f.States))
+	for i, state := range f.States {
+		states[i] = string(state)
+	}
+
+	values := filterValues{}
+	values.list("id", f.Ids)
+	values.list("name", f.Names)
+	values.list("omnistack_cluster_id", f.OmniStackClusterIds)
+	values.list("omnistack_cluster_name", f.OmniStackClusterNames)
+	values.list("compute_cluster_parent_hypervisor_object_id", f.ComputeClusterParentHypervisorObjectIds)
+	values.list("compute_cluster_parent_name", f.ComputeClusterParentNames)
+	values.list("hypervisor_management_system", f.HypervisorManagementSystems)
+	values.list("hypervisor_management_system_name", f.HypervisorManagementSystemNames)
+	values.list("datastore_id", f.DatastoreIds)
+	values.list("datastore_name", f.DatastoreNames)
+	values.list("policy_id", f.PolicyIds)
+	values.list("policy_name", f.PolicyNames)
+	values.list("hypervisor_object_id", f.HypervisorObjectIds)
+	values.time("created_after", f.CreatedAfter)
+	values.time("created_before", f.CreatedBefore)
+	values.list("state", states)
+	values.list("app_aware_vm_status", f.AppAwareVMStatuses)
+	values.bool("hypervisor_is_template", f.HypervisorIsTemplate)
+	values.string("host_id", f.HostId)
+
+	return values
 }
 
 // GetAll returns all the virtual machines filtered by the query parameters.
+// Build the filters with VirtualMachineFilter.
 // Filters:
 //   id: The unique identifier (UID) of the virtual_machines to return
 //     Accepts: Single value, comma-separated list
@@ -104,6 +176,11 @@ type VirtualMachine struct {
 //   hypervisor_is_template: An indicator that shows if the virtual machine is a template.
 //   host_id: The unique identifier (UID) of the virtual_machine host.
 func (v *VirtualMachineResource) GetAll(params GetAllParams) (*VirtualMachineList, error) {
+	return v.GetAllWithContext(context.Background(), params)
+}
+
+// GetAllWithContext is like GetAll but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) GetAllWithContext(ctx context.Context, params GetAllParams) (*VirtualMachineList, error) {
 	var (
 		path   = "/virtual_machines"
 		vmList VirtualMachineList
@@ -111,7 +188,7 @@ func (v *VirtualMachineResource) GetAll(params GetAllParams) (*VirtualMachineLis
 
 	qrStr := params.QueryString()
 
-	resp, err := v.client.DoRequest("GET", path, qrStr, nil, nil)
+	resp, err := v.client.DoRequestWithContext(ctx, "GET", path, qrStr, nil, nil)
 	if err != nil {
 		return &vmList, err
 	}
@@ -121,30 +198,114 @@ func (v *VirtualMachineResource) GetAll(params GetAllParams) (*VirtualMachineLis
 		return &vmList, err
 	}
 
+	for _, vm := range vmList.Members {
+		vm.client = v.client
+	}
+
 	return &vmList, nil
 }
 
+// GetAllPages returns all the virtual machines matching the query parameters,
+// in a single list. The pages after the first one are fetched concurrently,
+// see Client.PageWorkers, with the page size the OVC returned for the first one,
+// which is params.Limit unless the OVC caps it.
+// Returns ErrCountChanged if the number of virtual machines keeps changing.
+// Returns ErrShortPage if a page comes back with fewer members than expected.
+func (v *VirtualMachineResource) GetAllPages(params GetAllParams) (*VirtualMachineList, error) {
+	return v.GetAllPagesWithContext(context.Background(), params)
+}
+
+// GetAllPagesWithContext is like GetAllPages but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) GetAllPagesWithContext(ctx context.Context, params GetAllParams) (*VirtualMachineList, error) {
+	var (
+		mu    sync.Mutex
+		pages map[int][]*VirtualMachine
+	)
+
+	count, err := fetchAllPages(ctx, params, v.client.PageWorkers, func(ctx context.Context, page int, params GetAllParams) (int, int, error) {
+		vmList, err := v.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if page == 0 {
+			pages = map[int][]*VirtualMachine{}
+		}
+		pages[page] = vmList.Members
+
+		return vmList.Count, len(vmList.Members), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vmList := &VirtualMachineList{Offset: params.Offset, Count: count}
+	for page := 0; page < len(pages); page++ {
+		vmList.Members = append(vmList.Members, pages[page]...)
+	}
+
+	return vmList, nil
+}
+
+// Iterate calls fn with every virtual machine matching the query parameters,
+// fetching the pages of params.Limit members from params.Offset as needed.
+// Return ErrStopIteration from fn to stop early.
+func (v *VirtualMachineResource) Iterate(params GetAllParams, fn func(vm *VirtualMachine) error) error {
+	return v.IterateWithContext(context.Background(), params, fn)
+}
+
+// IterateWithContext is like Iterate but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) IterateWithContext(ctx context.Context, params GetAllParams, fn func(vm *VirtualMachine) error) error {
+	return paginate(params, func(params GetAllParams) (int, int, error) {
+		vmList, err := v.GetAllWithContext(ctx, params)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, vm := range vmList.Members {
+			err = fn(vm)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		return vmList.Count, len(vmList.Members), nil
+	})
+}
+
 // GetBy searches for VM resources with single filter.
 func (v *VirtualMachineResource) GetBy(field_name string, value string) ([]*VirtualMachine, error) {
+	return v.GetByWithContext(context.Background(), field_name, value)
+}
+
+// GetByWithContext is like GetBy but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) GetByWithContext(ctx context.Context, field_name string, value string) ([]*VirtualMachine, error) {
+	var vms []*VirtualMachine
 	filters := map[string]string{field_name: value}
-	vmList, err := v.GetAll(GetAllParams{Filters: filters})
+	err := v.IterateWithContext(ctx, GetAllParams{Filters: filters}, func(vm *VirtualMachine) error {
+		vms = append(vms, vm)
+		return nil
+	})
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	vms := vmList.Members
-
 	return vms, nil
 }
 
 // GetByName searches for a VM by its name
 func (v *VirtualMachineResource) GetByName(name string) (*VirtualMachine, error) {
-	vms, err := v.GetBy("name", name)
+	return v.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext is like GetByName but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) GetByNameWithContext(ctx context.Context, name string) (*VirtualMachine, error) {
+	vms, err := v.GetByWithContext(ctx, "name", name)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -153,15 +314,19 @@ func (v *VirtualMachineResource) GetByName(name string) (*VirtualMachine, error)
 		return vm, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // GetById searches for a VM by its id
 func (v *VirtualMachineResource) GetById(id string) (*VirtualMachine, error) {
-	vms, err := v.GetBy("id", id)
+	return v.GetByIdWithContext(context.Background(), id)
+}
+
+// GetByIdWithContext is like GetById but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) GetByIdWithContext(ctx context.Context, id string) (*VirtualMachine, error) {
+	vms, err := v.GetByWithContext(ctx, "id", id)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -170,17 +335,42 @@ func (v *VirtualMachineResource) GetById(id string) (*VirtualMachine, error) {
 		return vm, nil
 	}
 
-	return nil, errors.New("Resource doesn't exist")
+	return nil, ErrNotFound
 }
 
 // SetPolicyForMultipleVMs sets a policy for list of VM resources.
 func (v *VirtualMachineResource) SetPolicyForMultipleVMs(policy *Policy, vms []*VirtualMachine) error {
+	return v.SetPolicyForMultipleVMsWithContext(context.Background(), policy, vms)
+}
+
+// SetPolicyForMultipleVMsWithContext is like SetPolicyForMultipleVMs but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) SetPolicyForMultipleVMsWithContext(ctx context.Context, policy *Policy, vms []*VirtualMachine) error {
+	task, err := v.SetPolicyForMultipleVMsAsyncWithContext(ctx, policy, vms)
+	if err != nil {
+		return err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetPolicyForMultipleVMsAsync starts setting the policy of the VMs and returns the task right away.
+func (v *VirtualMachineResource) SetPolicyForMultipleVMsAsync(policy *Policy, vms []*VirtualMachine) (*Task, error) {
+	return v.SetPolicyForMultipleVMsAsyncWithContext(context.Background(), policy, vms)
+}
+
+// SetPolicyForMultipleVMsAsyncWithContext is like SetPolicyForMultipleVMsAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) SetPolicyForMultipleVMsAsyncWithContext(ctx context.Context, policy *Policy, vms []*VirtualMachine) (*Task, error) {
 	var (
 		path = fmt.Sprintf("/virtual_machines/set_policy")
 	)
 
 	if len(vms) < 1 {
-		return errors.New("Pass a list of VM resoures")
+		return nil, errors.New("Pass a list of VM resoures")
 	}
 
 	vm_ids := []string{}
@@ -190,109 +380,169 @@ func (v *VirtualMachineResource) SetPolicyForMultipleVMs(policy *Policy, vms []*
 
 	body := map[string]interface{}{"policy_id": policy.Id, "virtual_machine_id": vm_ids}
 
-	resp, err := v.client.DoRequest("POST", path, "", body, nil)
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
 	if err != nil {
-		return err
-	}
-
-	_, err = commonClient.Tasks.WaitForTask(resp)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return v.client.startTask(resp, "VirtualMachines.SetPolicyForMultipleVMs", "")
 }
 
 // SetPolicy sets policy for single VM resource.
 func (v *VirtualMachine) SetPolicy(policy *Policy) error {
-	var (
-		path = fmt.Sprintf("/virtual_machines/%s/set_policy", v.Id)
-	)
+	return v.SetPolicyWithContext(context.Background(), policy)
+}
 
-	body := map[string]string{"policy_id": policy.Id}
-	resp, err := commonClient.DoRequest("POST", path, "", body, nil)
+// SetPolicyWithContext is like SetPolicy but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) SetPolicyWithContext(ctx context.Context, policy *Policy) error {
+	task, err := v.SetPolicyAsyncWithContext(ctx, policy)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
 	if len(task.AffectedResources) < 1 {
-		err_message := "Set policy was not successful. Error code:" + string(task.ErrorCode)
-		return errors.New(err_message)
+		return newTaskError(task)
 	}
 
 	return nil
 }
 
-// Clone creates a clone of the VM.
-func (v *VirtualMachine) Clone(new_vm_name string, app_consistent bool) (*VirtualMachine, error) {
+// SetPolicyAsync starts setting the policy of the VM and returns the task right away.
+func (v *VirtualMachine) SetPolicyAsync(policy *Policy) (*Task, error) {
+	return v.SetPolicyAsyncWithContext(context.Background(), policy)
+}
+
+// SetPolicyAsyncWithContext is like SetPolicyAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) SetPolicyAsyncWithContext(ctx context.Context, policy *Policy) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
 	var (
-		path = fmt.Sprintf("/virtual_machines/%s/clone", v.Id)
+		path = fmt.Sprintf("/virtual_machines/%s/set_policy", v.Id)
 	)
 
-	body := map[string]interface{}{"virtual_machine_name": new_vm_name,
-		"app_consistent": app_consistent}
-	resp, err := commonClient.DoRequest("POST", path, "", body, nil)
+	body := map[string]string{"policy_id": policy.Id}
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	return v.client.startTask(resp, "VirtualMachine.SetPolicy", v.Id)
+}
+
+// Clone creates a clone of the VM.
+func (v *VirtualMachine) Clone(new_vm_name string, app_consistent bool) (*VirtualMachine, error) {
+	return v.CloneWithContext(context.Background(), new_vm_name, app_consistent)
+}
+
+// CloneWithContext is like Clone but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) CloneWithContext(ctx context.Context, new_vm_name string, app_consistent bool) (*VirtualMachine, error) {
+	task, err := v.CloneAsyncWithContext(ctx, new_vm_name, app_consistent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
 	resources := task.AffectedResources
 	if len(resources) < 1 {
-		err_message := "Clone was not successful. Error code:" + string(task.ErrorCode)
-		return nil, errors.New(err_message)
+		return nil, newTaskError(task)
 	}
 
 	resource_id := resources[0].ObjectId
-	clonedVM, err := commonClient.VirtualMachines.GetById(resource_id)
+	clonedVM, err := v.client.VirtualMachines.GetByIdWithContext(ctx, resource_id)
 
-	return clonedVM, nil
+	return clonedVM, err
 }
 
-// Move moves a VM from one datastore to another.
-func (v *VirtualMachine) Move(vm_name string, datastore *Datastore) (*VirtualMachine, error) {
+// CloneAsync starts cloning the VM and returns the task right away.
+// The id of the clone is the first affected object of the finished task.
+func (v *VirtualMachine) CloneAsync(new_vm_name string, app_consistent bool) (*Task, error) {
+	return v.CloneAsyncWithContext(context.Background(), new_vm_name, app_consistent)
+}
+
+// CloneAsyncWithContext is like CloneAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) CloneAsyncWithContext(ctx context.Context, new_vm_name string, app_consistent bool) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
 	var (
-		path = fmt.Sprintf("/virtual_machines/%s/move", v.Id)
+		path = fmt.Sprintf("/virtual_machines/%s/clone", v.Id)
 	)
 
-	body := map[string]interface{}{"virtual_machine_name": vm_name,
-		"destination_datastore_id": datastore.Id}
+	body := map[string]interface{}{"virtual_machine_name": new_vm_name,
+		"app_consistent": app_consistent}
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := commonClient.DoRequest("POST", path, "", body, nil)
+	return v.client.startTask(resp, "VirtualMachine.Clone", v.Id)
+}
+
+// Move moves a VM from one datastore to another.
+func (v *VirtualMachine) Move(vm_name string, datastore *Datastore) (*VirtualMachine, error) {
+	return v.MoveWithContext(context.Background(), vm_name, datastore)
+}
+
+// MoveWithContext is like Move but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) MoveWithContext(ctx context.Context, vm_name string, datastore *Datastore) (*VirtualMachine, error) {
+	task, err := v.MoveAsyncWithContext(ctx, vm_name, datastore)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
 	resources := task.AffectedResources
 	if len(resources) < 1 {
-		err_message := "Move was not successful. Error code:" + string(task.ErrorCode)
-		return nil, errors.New(err_message)
+		return nil, newTaskError(task)
 	}
 
 	resource_id := resources[0].ObjectId
-	clonedVM, err := commonClient.VirtualMachines.GetById(resource_id)
+	clonedVM, err := v.client.VirtualMachines.GetByIdWithContext(ctx, resource_id)
+
+	return clonedVM, err
+}
+
+// MoveAsync starts moving the VM and returns the task right away.
+// The id of the moved VM is the first affected object of the finished task.
+func (v *VirtualMachine) MoveAsync(vm_name string, datastore *Datastore) (*Task, error) {
+	return v.MoveAsyncWithContext(context.Background(), vm_name, datastore)
+}
+
+// MoveAsyncWithContext is like MoveAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) MoveAsyncWithContext(ctx context.Context, vm_name string, datastore *Datastore) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/virtual_machines/%s/move", v.Id)
+	)
 
-	return clonedVM, nil
+	body := map[string]interface{}{"virtual_machine_name": vm_name,
+		"destination_datastore_id": datastore.Id}
+
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.client.startTask(resp, "VirtualMachine.Move", v.Id)
 }
 
 // CreateBackup request body
@@ -320,57 +570,90 @@ type CreateBackupRequest struct {
 
 // CreateBackup creates a back of the VM.
 func (v *VirtualMachine) CreateBackup(req *CreateBackupRequest, dest *OmniStackCluster) (*Backup, error) {
-	var (
-		path = fmt.Sprintf("/virtual_machines/%s/backup", v.Id)
-	)
-
-	if dest != nil {
-		req.Destination = dest.Id
-	}
+	return v.CreateBackupWithContext(context.Background(), req, dest)
+}
 
-	resp, err := commonClient.DoRequest("POST", path, "", req, nil)
+// CreateBackupWithContext is like CreateBackup but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) CreateBackupWithContext(ctx context.Context, req *CreateBackupRequest, dest *OmniStackCluster) (*Backup, error) {
+	task, err := v.CreateBackupAsyncWithContext(ctx, req, dest)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
 	resources := task.AffectedResources
 	if len(resources) < 1 {
-		err_message := "Backup was not successful. Error code:" + string(task.ErrorCode)
-		return nil, errors.New(err_message)
+		return nil, newTaskError(task)
 	}
 
 	resource_id := resources[0].ObjectId
-	backup, err := commonClient.Backups.GetById(resource_id)
+	backup, err := v.client.Backups.GetByIdWithContext(ctx, resource_id)
+
+	return backup, err
+}
 
-	return backup, nil
+// CreateBackupAsync starts a backup of the VM and returns the task right away.
+// The id of the backup is the first affected object of the finished task.
+func (v *VirtualMachine) CreateBackupAsync(req *CreateBackupRequest, dest *OmniStackCluster) (*Task, error) {
+	return v.CreateBackupAsyncWithContext(context.Background(), req, dest)
+}
+
+// CreateBackupAsyncWithContext is like CreateBackupAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) CreateBackupAsyncWithContext(ctx context.Context, req *CreateBackupRequest, dest *OmniStackCluster) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/virtual_machines/%s/backup", v.Id)
+	)
+
+	if dest != nil {
+		req.Destination = dest.Id
+	}
+
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.client.startTask(resp, "VirtualMachine.CreateBackup", v.Id)
 }
 
 // GetBackups gets all the backups of a VM.
 func (v *VirtualMachine) GetBackups() (*BackupList, error) {
+	return v.GetBackupsWithContext(context.Background())
+}
+
+// GetBackupsWithContext is like GetBackups but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) GetBackupsWithContext(ctx context.Context) (*BackupList, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
 	var (
 		path       = fmt.Sprintf("/virtual_machines/%s/backups", v.Id)
 		backupList BackupList
 	)
 
-	resp, err := commonClient.DoRequest("GET", path, "", "", nil)
+	resp, err := v.client.DoRequestWithContext(ctx, "GET", path, "", "", nil)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
 	err = json.Unmarshal(resp, &backupList)
 	if err != nil {
-		log.Println(err)
 		return &backupList, err
 	}
 
+	for _, backup := range backupList.Members {
+		backup.client = v.client
+	}
+
 	return &backupList, nil
 }
 
@@ -394,35 +677,89 @@ type SetBackupParametersRequest struct {
 
 // SetBackupParameters sets the virtual machine backup parameters used for application consistent backups.
 func (v *VirtualMachine) SetBackupParameters(req *SetBackupParametersRequest) error {
-	var (
-		path = fmt.Sprintf("/virtual_machines/%s/backup_parameters", v.Id)
-	)
+	return v.SetBackupParametersWithContext(context.Background(), req)
+}
 
-	header := map[string]string{"Content-Type": "application/vnd.simplivity.v1.11+json"}
-	resp, err := commonClient.DoRequest("POST", path, "", req, header)
+// SetBackupParametersWithContext is like SetBackupParameters but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) SetBackupParametersWithContext(ctx context.Context, req *SetBackupParametersRequest) error {
+	task, err := v.SetBackupParametersAsyncWithContext(ctx, req)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	err = task.Wait(ctx)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
 	resources := task.AffectedResources
 	if len(resources) < 1 {
-		err_message := "Set backup parameters operation was not successful. Error code:" + string(task.ErrorCode)
-		return errors.New(err_message)
+		return newTaskError(task)
 	}
 
 	return nil
 }
 
+// SetBackupParametersAsync starts setting the backup parameters of the VM and returns the task right away.
+func (v *VirtualMachine) SetBackupParametersAsync(req *SetBackupParametersRequest) (*Task, error) {
+	return v.SetBackupParametersAsyncWithContext(context.Background(), req)
+}
+
+// SetBackupParametersAsyncWithContext is like SetBackupParametersAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) SetBackupParametersAsyncWithContext(ctx context.Context, req *SetBackupParametersRequest) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
+	var (
+		path = fmt.Sprintf("/virtual_machines/%s/backup_parameters", v.Id)
+	)
+
+	header := map[string]string{"Content-Type": "application/vnd.simplivity.v1.11+json"}
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", req, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.client.startTask(resp, "VirtualMachine.SetBackupParameters", v.Id)
+}
+
 // UpdatePowerState sets power state of the VM.
 // Valid states: on/off
+// Use Power to also wait until the VM reaches the power state.
 func (v *VirtualMachine) UpdatePowerState(state string) error {
+	return v.UpdatePowerStateWithContext(context.Background(), state)
+}
+
+// UpdatePowerStateWithContext is like UpdatePowerState but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) UpdatePowerStateWithContext(ctx context.Context, state string) error {
+	task, err := v.UpdatePowerStateAsyncWithContext(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(task.AffectedResources) < 1 {
+		return newTaskError(task)
+	}
+
+	return nil
+}
+
+// UpdatePowerStateAsync starts changing the power state of the VM and returns the task right away.
+func (v *VirtualMachine) UpdatePowerStateAsync(state string) (*Task, error) {
+	return v.UpdatePowerStateAsyncWithContext(context.Background(), state)
+}
+
+// UpdatePowerStateAsyncWithContext is like UpdatePowerStateAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) UpdatePowerStateAsyncWithContext(ctx context.Context, state string) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
 
 	var path string
 
@@ -432,26 +769,14 @@ func (v *VirtualMachine) UpdatePowerState(state string) error {
 		path = fmt.Sprintf("/virtual_machines/%s/power_on", v.Id)
 	} else {
 		error_message := "Pass a valid power state"
-		return errors.New(error_message)
+		return nil, errors.New(error_message)
 	}
 
 	req_header := map[string]string{"Content-Type": "application/vnd.simplivity.v1.11+json"}
-	resp, err := commonClient.DoRequest("POST", path, "", "", req_header)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	task, err := commonClient.Tasks.WaitForTask(resp)
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", "", req_header)
 	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	if len(task.AffectedResources) < 1 {
-		err_message := "Setting power state operation was not successful. Error code:" + string(task.ErrorCode)
-		return errors.New(err_message)
+		return nil, err
 	}
 
-	return nil
+	return v.client.startTask(resp, "VirtualMachine.UpdatePowerState", v.Id)
 }
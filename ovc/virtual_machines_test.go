@@ -34,7 +34,7 @@ func TestVMGetAllWithDefaultParameters(t *testing.T) {
 		t.Error(err)
 	}
 
-	vm := &VirtualMachine{Name: "testvm", Id: "1"}
+	vm := &VirtualMachine{Name: "testvm", Id: "1", client: client}
 	expected := &VirtualMachineList{Offset: 1,
 		Limit:   1,
 		Count:   1,
@@ -214,6 +214,50 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestCloneAsync(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	//Mock get by id request
+	mockGetVMById(apiHandler)
+
+	apiHandler.HandleFunc("/virtual_machines/1/clone", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	vm, err := client.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := vm.CloneAsync("testvm", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Done() {
+		t.Error("Task should be in progress")
+	}
+
+	err = task.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !task.Done() {
+		t.Error("Task should be done")
+	}
+
+	objects := task.AffectedObjects()
+	if len(objects) != 1 || objects[0].ObjectId != "1" {
+		t.Errorf("Affected objects = %v, expected the clone with id 1", objects)
+	}
+}
+
 func TestMove(t *testing.T) {
 	client, apiHandler, teardown := setup()
 	defer teardown()
@@ -277,7 +321,7 @@ func TestCreateBackup(t *testing.T) {
 		t.Error(err)
 	}
 
-	expected := &Backup{Name: "backup_name", Id: "1"}
+	expected := &Backup{Name: "backup_name", Id: "1", client: client}
 	if !reflect.DeepEqual(backup, expected) {
 		t.Errorf("Returned = %v, expected %v", backup, expected)
 	}
@@ -306,7 +350,7 @@ func TestGetBackups(t *testing.T) {
 		t.Error(err)
 	}
 
-	backup := &Backup{Name: "testbackup", Id: "1"}
+	backup := &Backup{Name: "testbackup", Id: "1", client: client}
 	expected := &BackupList{Offset: 1,
 		Limit:   500,
 		Count:   1,
@@ -0,0 +1,267 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PowerAction is an action changing the power state of a virtual machine.
+type PowerAction string
+
+// Power actions of the virtual machines
+// The OVC only offers a hard power off, it can't shut down the guest OS.
+const (
+	PowerOn  PowerAction = "power_on"
+	PowerOff PowerAction = "power_off"
+)
+
+// PowerState is the power state of a virtual machine in the hypervisor.
+// Unlike VirtualMachine.State, which tells whether the VM exists, it tells whether the VM runs.
+type PowerState string
+
+// Power states of the virtual machines
+const (
+	PowerStateOn  PowerState = "ON"
+	PowerStateOff PowerState = "OFF"
+)
+
+// Number of VMs powered on or off at the same time by PowerAll
+const defaultPowerWorkers = 4
+
+// ErrInvalidPowerAction is returned for power actions the OVC doesn't support.
+var ErrInvalidPowerAction = errors.New("Pass a valid power action")
+
+// ErrNoPowerState is returned when the OVC doesn't report the power state of a VM,
+// so it can't be confirmed.
+var ErrNoPowerState = errors.New("OVC reports no power state of the VM")
+
+// ErrPowerStateNotReached is matched by the errors of the VMs which
+// don't reach the power state within the timeout of the poll policy.
+var ErrPowerStateNotReached = errors.New("Power state not reached")
+
+// PowerStateError is returned when a VM doesn't reach the power state
+// within the timeout of the poll policy.
+// It holds the last known power state of the VM.
+type PowerStateError struct {
+	VMId     string
+	State    PowerState
+	Expected PowerState
+	Timeout  time.Duration
+}
+
+func (e *PowerStateError) Error() string {
+	return fmt.Sprintf("VM %s is still %s, expected %s after %v", e.VMId, e.State, e.Expected, e.Timeout)
+}
+
+// Is matches ErrPowerStateNotReached.
+func (e *PowerStateError) Is(target error) bool {
+	return target == ErrPowerStateNotReached
+}
+
+// state returns the power state the action leads to.
+func (a PowerAction) state() (PowerState, error) {
+	switch a {
+	case PowerOn:
+		return PowerStateOn, nil
+	case PowerOff:
+		return PowerStateOff, nil
+	}
+
+	return "", ErrInvalidPowerAction
+}
+
+// Power powers the VM on or off, and waits until the VM reaches the new power state.
+func (v *VirtualMachine) Power(action PowerAction) error {
+	return v.PowerWithContext(context.Background(), action)
+}
+
+// PowerWithContext is like Power but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) PowerWithContext(ctx context.Context, action PowerAction) error {
+	state, err := action.state()
+	if err != nil {
+		return err
+	}
+
+	task, err := v.PowerAsyncWithContext(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	err = task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(task.AffectedResources) < 1 {
+		return newTaskError(task)
+	}
+
+	return v.WaitForPowerStateWithContext(ctx, state)
+}
+
+// PowerAsync starts powering the VM on or off and returns the task right away.
+func (v *VirtualMachine) PowerAsync(action PowerAction) (*Task, error) {
+	return v.PowerAsyncWithContext(context.Background(), action)
+}
+
+// PowerAsyncWithContext is like PowerAsync but uses ctx for the requests to the OVC.
+func (v *VirtualMachine) PowerAsyncWithContext(ctx context.Context, action PowerAction) (*Task, error) {
+	if v.client == nil {
+		return nil, ErrNoClient
+	}
+
+	if _, err := action.state(); err != nil {
+		return nil, err
+	}
+
+	var (
+		path       = fmt.Sprintf("/virtual_machines/%s/%s", v.Id, action)
+		req_header = map[string]string{"Content-Type": "application/vnd.simplivity.v1.11+json"}
+	)
+
+	resp, err := v.client.DoRequestWithContext(ctx, "POST", path, "", "", req_header)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.client.startTask(resp, "VirtualMachine.Power", v.Id)
+}
+
+// WaitForPowerState re-reads the VM until it reaches the power state,
+// following the poll policy of the client.
+// PowerState of the VM is updated on the way.
+// Returns ErrNoPowerState if the OVC doesn't report the power state of the VM.
+func (v *VirtualMachine) WaitForPowerState(state PowerState) error {
+	return v.WaitForPowerStateWithContext(context.Background(), state)
+}
+
+// WaitForPowerStateWithContext is like WaitForPowerState but uses ctx for the requests to the OVC.
+// Polling stops and the context error is returned when ctx is done.
+func (v *VirtualMachine) WaitForPowerStateWithContext(ctx context.Context, state PowerState) error {
+	if v.client == nil {
+		return ErrNoClient
+	}
+
+	policy := v.client.pollPolicy(ctx)
+
+	waitCtx := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	for poll := 1; ; poll++ {
+		powerState, err := v.readPowerState(waitCtx)
+		if err != nil {
+			if waitCtx.Err() != nil {
+				return v.powerWaitError(ctx, state, policy)
+			}
+			return err
+		}
+
+		v.PowerState = powerState
+		if v.PowerState == state {
+			return nil
+		}
+
+		// Wait between the reads to avoid hitting the server continously
+		// Stop waiting as soon as the context is done
+		select {
+		case <-waitCtx.Done():
+			return v.powerWaitError(ctx, state, policy)
+		case <-time.After(policy.interval(poll)):
+		}
+	}
+}
+
+// readPowerState reads the power state of the VM, asking for the optional fields
+// so the OVC includes it.
+func (v *VirtualMachine) readPowerState(ctx context.Context) (PowerState, error) {
+	params := GetAllParams{Filters: map[string]string{"id": v.Id}, ShowOptionalFields: true}
+	vmList, err := v.client.VirtualMachines.GetAllWithContext(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	if len(vmList.Members) < 1 {
+		return "", ErrNotFound
+	}
+
+	powerState := vmList.Members[0].PowerState
+	if powerState == "" {
+		return "", ErrNoPowerState
+	}
+
+	return powerState, nil
+}
+
+// powerWaitError returns the error of a power state wait stopped
+// by the context of the caller or by the timeout of the poll policy.
+func (v *VirtualMachine) powerWaitError(ctx context.Context, state PowerState, policy *PollPolicy) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return &PowerStateError{VMId: v.Id, State: v.PowerState, Expected: state, Timeout: policy.Timeout}
+}
+
+// PowerAll powers the VMs on or off, at most workers VMs at a time,
+// and waits until they all reach the new power state.
+// Pass 0 workers for the default of 4.
+// Returns a *MultiError with the errors of the failed VMs by VM id.
+func (v *VirtualMachineResource) PowerAll(vms []*VirtualMachine, action PowerAction, workers int) error {
+	return v.PowerAllWithContext(context.Background(), vms, action, workers)
+}
+
+// PowerAllWithContext is like PowerAll but uses ctx for the requests to the OVC.
+func (v *VirtualMachineResource) PowerAllWithContext(ctx context.Context, vms []*VirtualMachine, action PowerAction, workers int) error {
+	if _, err := action.state(); err != nil {
+		return err
+	}
+
+	if workers < 1 {
+		workers = defaultPowerWorkers
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sem      = make(chan struct{}, workers)
+		multiErr = &MultiError{Errors: map[string]error{}}
+	)
+
+	for _, vm := range vms {
+		wg.Add(1)
+		go func(vm *VirtualMachine) {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				mu.Lock()
+				defer mu.Unlock()
+				multiErr.Errors[vm.Id] = ctx.Err()
+				return
+			case sem <- struct{}{}:
+			}
+			defer func() { <-sem }()
+
+			err := vm.PowerWithContext(ctx, action)
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				multiErr.Errors[vm.Id] = err
+			}
+		}(vm)
+	}
+	wg.Wait()
+
+	if len(multiErr.Errors) > 0 {
+		return multiErr
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package ovc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockVMPowerStates serves the VMs by id with the power states returned by state.
+// Like the OVC, the power state is only included with the optional fields.
+func mockVMPowerStates(apiHandler *http.ServeMux, state func(id string) PowerState) {
+	apiHandler.HandleFunc("/virtual_machines", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if r.URL.Query().Get("show_optional_fields") != "true" {
+			fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": 1, "virtual_machines":[{"name": "testvm%s", "id":"%s"}]}`, id, id)
+			return
+		}
+		fmt.Fprintf(w, `{"offset": 0, "limit": 500, "count": 1, "virtual_machines":[{"name": "testvm%s", "id":"%s", "hypervisor_virtual_machine_power_state":"%s"}]}`,
+			id, id, state(id))
+	})
+}
+
+func TestPower(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	var reads int32
+	mockVMPowerStates(apiHandler, func(id string) PowerState {
+		// The VM is still off right after the task completes
+		if atomic.AddInt32(&reads, 1) < 3 {
+			return PowerStateOff
+		}
+		return PowerStateOn
+	})
+
+	apiHandler.HandleFunc("/virtual_machines/1/power_on", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Content-Type", "application/vnd.simplivity.v1.11+json")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	vm, err := client.VirtualMachines.GetById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = vm.Power(PowerOn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vm.PowerState != PowerStateOn {
+		t.Errorf("Power state = %s, expected %s", vm.PowerState, PowerStateOn)
+	}
+}
+
+func TestPowerInvalidAction(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	vm := &VirtualMachine{Id: "1", client: client}
+
+	err := vm.Power(PowerAction("suspend"))
+	if !errors.Is(err, ErrInvalidPowerAction) {
+		t.Errorf("Returned %v, expected ErrInvalidPowerAction", err)
+	}
+
+	err = vm.UpdatePowerState("suspend")
+	if err == nil {
+		t.Error("UpdatePowerState with an invalid state should fail")
+	}
+}
+
+func TestUpdatePowerState(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	journal, remove := newTestJournal(t)
+	defer remove()
+	client.TaskJournal = journal
+
+	// The power state is not waited for, so the VM is never read
+	apiHandler.HandleFunc("/virtual_machines", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("UpdatePowerState should not read the VM")
+	})
+
+	apiHandler.HandleFunc("/virtual_machines/1/power_off", func(w http.ResponseWriter, r *http.Request) {
+		testRequestMethod(t, r, "POST")
+		testRequestHeader(t, r, "Content-Type", "application/vnd.simplivity.v1.11+json")
+		fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+	})
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	vm := &VirtualMachine{Id: "1", client: client}
+	err := vm.UpdatePowerState("off")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Operation != "VirtualMachine.UpdatePowerState" {
+		t.Errorf("Journal entries = %+v, expected the UpdatePowerState task", entries)
+	}
+}
+
+func TestWaitForPowerStateWithoutPowerState(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	// The OVC doesn't report the power state
+	mockGetVMById(apiHandler)
+
+	vm := &VirtualMachine{Id: "1", client: client}
+	err := vm.WaitForPowerState(PowerStateOn)
+	if !errors.Is(err, ErrNoPowerState) {
+		t.Errorf("Returned %v, expected ErrNoPowerState", err)
+	}
+}
+
+func TestWaitForPowerStateTimeout(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockVMPowerStates(apiHandler, func(id string) PowerState {
+		return PowerStateOn
+	})
+
+	vm := &VirtualMachine{Id: "1", client: client}
+
+	ctx := ContextWithPollPolicy(context.Background(),
+		&PollPolicy{InitialInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	err := vm.WaitForPowerStateWithContext(ctx, PowerStateOff)
+
+	var stateErr *PowerStateError
+	if !errors.As(err, &stateErr) || !errors.Is(err, ErrPowerStateNotReached) {
+		t.Fatalf("Returned %v, expected a PowerStateError", err)
+	}
+
+	if stateErr.State != PowerStateOn || stateErr.Expected != PowerStateOff {
+		t.Errorf("Power state error = %+v, expected ON instead of OFF", stateErr)
+	}
+}
+
+func TestPowerAll(t *testing.T) {
+	client, apiHandler, teardown := setup()
+	defer teardown()
+
+	mockVMPowerStates(apiHandler, func(id string) PowerState {
+		return PowerStateOff
+	})
+
+	var (
+		mu               sync.Mutex
+		running, maxRuns int
+	)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		id := id
+		apiHandler.HandleFunc("/virtual_machines/"+id+"/power_off", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			running++
+			if running > maxRuns {
+				maxRuns = running
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			if id == "4" {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"message": "VM is locked"}`)
+				return
+			}
+			fmt.Fprint(w, `{"task":{"state": "IN_PROGRESS", "id": "1", "percent_complete": 1, "affected_objects":[]}}`)
+		})
+	}
+
+	//Mock request to task endpoint
+	mockTaskRequest(apiHandler)
+
+	var vms []*VirtualMachine
+	for _, id := range []string{"1", "2", "3", "4"} {
+		vms = append(vms, &VirtualMachine{Id: id, client: client})
+	}
+
+	err := client.VirtualMachines.PowerAll(vms, PowerOff, 2)
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Returned %v, expected a MultiError", err)
+	}
+
+	if len(multiErr.Errors) != 1 || !errors.Is(multiErr.Errors["4"], ErrConflict) {
+		t.Errorf("Errors = %v, expected a conflict of VM 4", multiErr.Errors)
+	}
+
+	if maxRuns > 2 {
+		t.Errorf("%d VMs were powered off at the same time, expected at most 2", maxRuns)
+	}
+
+	for _, vm := range vms[:3] {
+		if vm.PowerState != PowerStateOff {
+			t.Errorf("Power state of VM %s = %s, expected %s", vm.Id, vm.PowerState, PowerStateOff)
+		}
+	}
+}